@@ -1,21 +1,36 @@
 package app
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"webapi/internal/config"
 	"webapi/internal/handlers"
+	"webapi/internal/logger"
 	"webapi/internal/middleware"
+	"webapi/internal/services"
+	"webapi/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 type App struct {
-	config *config.Config
-	db     *sql.DB
-	router *gin.Engine
+	config   *config.Config
+	db       *sql.DB
+	router   *gin.Engine
+	services *services.Services
+	handlers *handlers.Handlers
+	keys     *utils.KeySet
+	oidc     *utils.OIDCVerifier
+	certs    *utils.ClientCertMapper
 }
 
-func New(cfg *config.Config, database *sql.DB) *App {
+func New(cfg *config.Config, database *sql.DB, keys *utils.KeySet, oidc *utils.OIDCVerifier, certs *utils.ClientCertMapper) *App {
 	// 设置 Gin 模式
 	if cfg.LogLevel == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -30,10 +45,25 @@ func New(cfg *config.Config, database *sql.DB) *App {
 	router.Use(gin.Recovery())
 	router.Use(middleware.CORS())
 
+	// 旧 token 的兼容选项将在下一个版本默认关闭
+	if cfg.JWT.AllowUnscopedTokens {
+		logger.Warn("API_ALLOW_UNSCOPED_TOKENS is enabled. Tokens without projects/scopes claims are deprecated; set it to false once all tokens are reissued")
+	}
+	if cfg.JWT.AllowTokensWithoutJTI {
+		logger.Warn("API_ALLOW_TOKENS_WITHOUT_JTI is enabled. Tokens without jti are deprecated; set it to false once all tokens are reissued")
+	}
+
+	svc := services.New(cfg, database)
+
 	app := &App{
-		config: cfg,
-		db:     database,
-		router: router,
+		config:   cfg,
+		db:       database,
+		router:   router,
+		services: svc,
+		handlers: handlers.New(cfg, database, svc, keys),
+		keys:     keys,
+		oidc:     oidc,
+		certs:    certs,
 	}
 
 	// 设置路由
@@ -42,12 +72,57 @@ func New(cfg *config.Config, database *sql.DB) *App {
 	return app
 }
 
+// Run 启动 HTTP 服务，收到退出信号后优雅关闭并等待后台任务完成
 func (a *App) Run(addr string) error {
-	return a.router.Run(addr)
+	server := &http.Server{
+		Addr:    addr,
+		Handler: a.router,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 配置了证书时由服务端直接终止 TLS
+	if a.config.TLS.CertFile != "" {
+		tlsConfig, err := utils.ServerTLSConfig(a.config.TLS)
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = tlsConfig
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			errCh <- server.ListenAndServeTLS(a.config.TLS.CertFile, a.config.TLS.KeyFile)
+			return
+		}
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		a.services.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	a.services.Close()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
 }
 
 func (a *App) setupRoutes() {
-	h := handlers.New(a.config, a.db)
+	h := a.handlers
 
 	// 静态文件和文档
 	a.router.GET("/", h.RedirectToAPI)
@@ -55,25 +130,47 @@ func (a *App) setupRoutes() {
 	a.router.GET("/v2/docs", h.ServeDocs)
 	a.router.GET("/v2/github/*path", h.ProxyGithubApi)
 	a.router.GET("/v2/api", h.ServeAPISpec)
+	a.router.GET("/.well-known/jwks.json", h.ServeJWKS)
 
 	// API 路由组
 	v2 := a.router.Group("/v2")
 	{
-		// 项目相关
-		v2.GET("/projects", h.GetProjects)
-		v2.GET("/projects/:project", h.GetProject)
-		v2.GET("/projects/:project/versions/:version", h.GetVersion)
-		v2.GET("/projects/:project/versions/:version/builds", h.GetVersionBuilds)
-		v2.GET("/projects/:project/versions/:version/builds/:build", h.GetBuild)
-		v2.GET("/projects/:project/versions/:version/latestGroupBuildId", h.GetLatestGroupBuildId)
-		v2.GET("/projects/:project/versions/:version/differ/:verRef", h.GetVersionDiffer)
-		v2.GET("/projects/:project/version_group/:family", h.GetVersionGroup)
-		v2.GET("/projects/:project/version_group/:family/builds", h.GetVersionGroupBuilds)
+		// 项目相关，查询接口支持 ETag 条件请求与进程内缓存
+		query := v2.Group("",
+			middleware.ConditionalGet(a.services.Responses, a.config.Cache.Control),
+			middleware.CacheResponses(a.services.Responses),
+		)
+		query.GET("/projects", h.GetProjects)
+		query.GET("/projects/:project", h.GetProject)
+		query.GET("/projects/:project/versions/:version", h.GetVersion)
+		query.GET("/projects/:project/versions/:version/builds", h.GetVersionBuilds)
+		query.GET("/projects/:project/versions/:version/builds/:build", h.GetBuild)
+		query.GET("/projects/:project/versions/:version/latestGroupBuildId", h.GetLatestGroupBuildId)
+		query.GET("/projects/:project/versions/:version/differ/:verRef", h.GetVersionDiffer)
+		query.GET("/projects/:project/versions/:version/changelog", h.GetVersionChangelog)
+		query.GET("/projects/:project/version_group/:family", h.GetVersionGroup)
+		query.GET("/projects/:project/version_group/:family/builds", h.GetVersionGroupBuilds)
+		query.GET("/projects/:project/version_group/:family/changelog", h.GetVersionGroupChangelog)
+		query.GET("/projects/:project/artifacts/:sha256", h.GetArtifact)
+
+		// 下载
 		v2.GET("/projects/:project/versions/:version/builds/:build/downloads/:download", h.DownloadBuild)
+		v2.HEAD("/projects/:project/versions/:version/builds/:build/downloads/:download", h.DownloadBuild)
+		v2.GET("/projects/:project/artifacts/:sha256/download", h.DownloadArtifact)
+		v2.HEAD("/projects/:project/artifacts/:sha256/download", h.DownloadArtifact)
 
 		// 需要认证的路由
 		authenticated := v2.Group("/")
-		authenticated.Use(middleware.Authentication(a.config.JWT))
+		authenticated.Use(middleware.Audit(a.services.Audit))
+		authenticated.Use(middleware.Authentication(middleware.Authenticator{
+			JWT:               a.config.JWT,
+			Keys:              a.keys,
+			Revocations:       a.services.Revocation,
+			GitHubOIDC:        a.oidc,
+			ServiceAccounts:   a.services.Accounts,
+			ClientCerts:       a.certs,
+			RequireClientCert: a.certs != nil && a.config.TLS.RequireClientCert,
+		}))
 		{
 			// 提交
 			authenticated.POST("/commit/build", h.CommitBuild)
@@ -81,6 +178,29 @@ func (a *App) setupRoutes() {
 
 			// 删除
 			authenticated.POST("/delete/build/download_source", h.DeleteDownloadSource)
+
+			// 签名下载链接
+			authenticated.POST("/sign/download", h.SignDownload)
+
+			// 统计
+			authenticated.GET("/stats/downloads/:project", h.GetDownloadStats)
+
+			// 管理
+			authenticated.POST("/admin/tokens/revoke", h.RevokeToken)
+			authenticated.GET("/admin/tokens/revoked", h.GetRevokedTokens)
+			authenticated.GET("/admin/audit", h.GetAuditLog)
+			authenticated.GET("/admin/cache", h.GetCacheStats)
+
+			// 项目、版本组与版本
+			authenticated.POST("/admin/projects", h.CreateProject)
+			authenticated.PATCH("/admin/projects/:project", h.UpdateProject)
+			authenticated.DELETE("/admin/projects/:project", h.DeleteProject)
+			authenticated.POST("/admin/projects/:project/version_group", h.CreateVersionGroup)
+			authenticated.PATCH("/admin/projects/:project/version_group/:family", h.UpdateVersionGroup)
+			authenticated.DELETE("/admin/projects/:project/version_group/:family", h.DeleteVersionGroup)
+			authenticated.POST("/admin/projects/:project/versions", h.CreateVersion)
+			authenticated.PATCH("/admin/projects/:project/versions/:version", h.UpdateVersion)
+			authenticated.DELETE("/admin/projects/:project/versions/:version", h.DeleteVersion)
 		}
 	}
 
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/utils"
+)
+
+const usage = `Usage:
+  webapi                      Start the API server
+  webapi keygen [flags]       Generate a signing key pair for API_ALGO
+  webapi token issue [flags]  Issue a token signed with API_PRIVATE_KEY
+  webapi service-account create|rotate [flags]
+                              Create a service account or rotate its API key
+  webapi service-account list List service accounts
+`
+
+// Run 执行命令行子命令，args 不包含程序名
+func Run(args []string) error {
+	return run(args, os.Stdout)
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", usage)
+	}
+
+	switch args[0] {
+	case "keygen":
+		return keygen(args[1:], config.LoadJWT(), out)
+	case "token":
+		if len(args) < 2 || args[1] != "issue" {
+			return fmt.Errorf("unknown token command\n%s", usage)
+		}
+		return issueToken(args[2:], config.LoadJWT(), out)
+	case "service-account":
+		return serviceAccount(args[1:], out)
+	case "help", "-h", "--help":
+		fmt.Fprint(out, usage)
+		return nil
+	}
+
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
+
+func keygen(args []string, jwtConfig config.JWTConfig, out io.Writer) error {
+	flags := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	algorithm := flags.String("alg", jwtConfig.Algorithm, "signing algorithm, defaults to API_ALGO")
+	printJWK := flags.Bool("jwk", false, "also print the public key as a JWK for API_JWKS_PATH")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	privatePEM, publicPEM, err := utils.GenerateKeyPair(*algorithm)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Public Key: \n%s\n", publicPEM)
+	fmt.Fprintf(out, "Private Key: \n%s", privatePEM)
+
+	if *printJWK {
+		signer, err := utils.ParsePrivateKey(privatePEM)
+		if err != nil {
+			return err
+		}
+		jwk, err := utils.PublicKeyJWK(signer.Public(), *algorithm)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(jwk, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\nJWK: \n%s\n", data)
+	}
+
+	return nil
+}
+
+func issueToken(args []string, jwtConfig config.JWTConfig, out io.Writer) error {
+	flags := flag.NewFlagSet("token issue", flag.ContinueOnError)
+	audience := flags.String("aud", "", "comma separated endpoints the token may call, e.g. /v2/commit/build or *")
+	projects := flags.String("projects", "", "comma separated projects the token may write to, or *")
+	scopes := flags.String("scopes", utils.ScopeCommitBuild+","+utils.ScopeManageDownloadSources, "comma separated actions: commit_build, manage_download_sources, manage_versions, delete, admin")
+	ttl := flags.String("ttl", "365d", "token lifetime, e.g. 720h, 90d or 1y")
+	kid := flags.String("kid", "", "key id header, defaults to the id of the matching trusted key")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *audience == "" || *projects == "" {
+		return fmt.Errorf("--aud and --projects are required")
+	}
+	if jwtConfig.PrivateKey == "" {
+		return fmt.Errorf("API_PRIVATE_KEY is not set")
+	}
+
+	lifetime, err := parseTTL(*ttl)
+	if err != nil {
+		return err
+	}
+
+	// 未指定 kid 时使用服务端受信任公钥中对应的 kid，保证服务端能选中正确的公钥
+	keys, keysErr := utils.LoadKeySet(jwtConfig)
+	keyID := *kid
+	if keyID == "" && keysErr == nil {
+		signer, err := utils.ParsePrivateKey(jwtConfig.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("failed to parse API_PRIVATE_KEY: %w", err)
+		}
+		if id, ok := keys.KeyID(signer.Public()); ok {
+			keyID = id
+		}
+	}
+
+	token, jti, err := utils.IssueToken(jwtConfig, utils.TokenOptions{
+		Audience: splitList(*audience),
+		Projects: splitList(*projects),
+		Scopes:   splitList(*scopes),
+		TTL:      lifetime,
+		KeyID:    keyID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 用服务端的验证逻辑校验一遍，避免签发服务端不接受的 token
+	if keysErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load verification keys, token was not verified: %v\n", keysErr)
+	} else if _, err := utils.ValidateJWT(token, keys, jwtConfig); err != nil {
+		return fmt.Errorf("the server would reject the issued token: %w", err)
+	}
+
+	fmt.Fprintf(out, "Token ID (jti): %s\n", jti)
+	fmt.Fprintf(out, "Token: \n%s\n", token)
+
+	return nil
+}
+
+// parseTTL 在 time.ParseDuration 基础上支持 d（天）与 y（365 天）单位
+func parseTTL(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "y": 365 * 24 * time.Hour} {
+		if strings.HasSuffix(value, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid ttl %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid ttl %q", value)
+	}
+	return d, nil
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
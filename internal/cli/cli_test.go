@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+)
+
+func TestParseTTL(t *testing.T) {
+	cases := map[string]time.Duration{
+		"720h": 720 * time.Hour,
+		"90d":  90 * 24 * time.Hour,
+		"1y":   365 * 24 * time.Hour,
+	}
+	for value, want := range cases {
+		if got, err := parseTTL(value); err != nil || got != want {
+			t.Errorf("parseTTL(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+
+	for _, value := range []string{"", "0d", "-1h", "abc"} {
+		if _, err := parseTTL(value); err == nil {
+			t.Errorf("expected parseTTL(%q) to fail", value)
+		}
+	}
+}
+
+func TestKeygenAndIssueToken(t *testing.T) {
+	for _, algorithm := range []string{"ES256", "EdDSA"} {
+		t.Run(algorithm, func(t *testing.T) {
+			var keys bytes.Buffer
+			if err := keygen([]string{"--alg", algorithm}, config.JWTConfig{}, &keys); err != nil {
+				t.Fatalf("keygen: %v", err)
+			}
+
+			pemBlock := func(kind string) string {
+				re := regexp.MustCompile(`(?s)-----BEGIN ` + kind + `-----.*?-----END ` + kind + `-----\n`)
+				return re.FindString(keys.String())
+			}
+
+			jwtConfig := config.JWTConfig{
+				PublicKey:  pemBlock("PUBLIC KEY"),
+				PrivateKey: pemBlock("PRIVATE KEY"),
+				Issuer:     "MenthaMC",
+				Subject:    "mentha-ci",
+				Algorithm:  algorithm,
+			}
+
+			var out bytes.Buffer
+			args := []string{"--aud", "/v2/commit/*", "--projects", "mint", "--ttl", "30d"}
+			if err := issueToken(args, jwtConfig, &out); err != nil {
+				t.Fatalf("issueToken: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			token := lines[len(lines)-1]
+
+			verificationKeys, err := utils.LoadKeySet(jwtConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := utils.ValidateJWT(token, verificationKeys, jwtConfig)
+			if err != nil {
+				t.Fatalf("ValidateJWT: %v", err)
+			}
+			if err := utils.CheckScope(claims, utils.ScopeCommitBuild, "mint", false); err != nil {
+				t.Errorf("expected commit_build scope on mint: %v", err)
+			}
+			if _, ok := claims["jti"].(string); !ok {
+				t.Error("expected issued token to carry a jti")
+			}
+		})
+	}
+}
+
+type memoryServiceAccountStore struct {
+	accounts map[string]*models.ServiceAccount
+	keys     map[string]string
+}
+
+func (s *memoryServiceAccountStore) Create(name string, projects, scopes []string, expiresAt *time.Time) (*models.ServiceAccount, string, error) {
+	s.accounts[name] = &models.ServiceAccount{Name: name, Projects: projects, Scopes: scopes, ExpiresAt: expiresAt}
+	return s.Rotate(name, nil)
+}
+
+func (s *memoryServiceAccountStore) Rotate(name string, expiresAt *time.Time) (*models.ServiceAccount, string, error) {
+	account, ok := s.accounts[name]
+	if !ok {
+		return nil, "", fmt.Errorf("service account %s not found", name)
+	}
+	if expiresAt != nil {
+		account.ExpiresAt = expiresAt
+	}
+	key, prefix, _, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	account.KeyPrefix = prefix
+	s.keys[name] = key
+	return account, key, nil
+}
+
+func (s *memoryServiceAccountStore) GetAll() ([]models.ServiceAccount, error) {
+	var accounts []models.ServiceAccount
+	for _, account := range s.accounts {
+		accounts = append(accounts, *account)
+	}
+	return accounts, nil
+}
+
+func TestServiceAccountCommands(t *testing.T) {
+	store := &memoryServiceAccountStore{accounts: map[string]*models.ServiceAccount{}, keys: map[string]string{}}
+
+	var out bytes.Buffer
+	if err := runServiceAccount([]string{"create", "--name", "mint-ci", "--projects", "mint", "--expires", "90d"}, store, &out); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	created := store.keys["mint-ci"]
+	if !strings.Contains(out.String(), created) {
+		t.Errorf("expected the new key to be printed, got %q", out.String())
+	}
+	if account := store.accounts["mint-ci"]; account.ExpiresAt == nil || len(account.Scopes) != 2 {
+		t.Errorf("unexpected account %+v", account)
+	}
+
+	out.Reset()
+	if err := runServiceAccount([]string{"rotate", "--name", "mint-ci"}, store, &out); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if store.keys["mint-ci"] == created || !strings.Contains(out.String(), store.keys["mint-ci"]) {
+		t.Error("expected rotate to print a new key")
+	}
+
+	if err := runServiceAccount([]string{"create", "--name", "missing-projects"}, store, &out); err == nil {
+		t.Error("expected create without --projects to fail")
+	}
+	if err := runServiceAccount([]string{"rotate", "--name", "unknown"}, store, &out); err == nil {
+		t.Error("expected rotating an unknown account to fail")
+	}
+}
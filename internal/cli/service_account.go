@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/database"
+	"webapi/internal/models"
+	"webapi/internal/services"
+	"webapi/internal/utils"
+)
+
+// serviceAccountStore 服务账号的持久化操作，测试中可替换
+type serviceAccountStore interface {
+	Create(name string, projects, scopes []string, expiresAt *time.Time) (*models.ServiceAccount, string, error)
+	Rotate(name string, expiresAt *time.Time) (*models.ServiceAccount, string, error)
+	GetAll() ([]models.ServiceAccount, error)
+}
+
+// serviceAccount 连接数据库后执行 service-account 子命令
+func serviceAccount(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing service-account command\n%s", usage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := database.Init(cfg.Database.URL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return runServiceAccount(args, services.NewServiceAccountService(db), out)
+}
+
+func runServiceAccount(args []string, store serviceAccountStore, out io.Writer) error {
+	switch args[0] {
+	case "create":
+		return createServiceAccount(args[1:], store, out)
+	case "rotate":
+		return rotateServiceAccount(args[1:], store, out)
+	case "list":
+		return listServiceAccounts(store, out)
+	}
+
+	return fmt.Errorf("unknown service-account command %q\n%s", args[0], usage)
+}
+
+func createServiceAccount(args []string, store serviceAccountStore, out io.Writer) error {
+	flags := flag.NewFlagSet("service-account create", flag.ContinueOnError)
+	name := flags.String("name", "", "unique service account name")
+	projects := flags.String("projects", "", "comma separated projects the key may write to, or *")
+	scopes := flags.String("scopes", utils.ScopeCommitBuild+","+utils.ScopeManageDownloadSources, "comma separated actions: commit_build, manage_download_sources, manage_versions, delete, admin")
+	expires := flags.String("expires", "", "optional key lifetime, e.g. 720h, 90d or 1y")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" || *projects == "" {
+		return fmt.Errorf("--name and --projects are required")
+	}
+	expiresAt, err := parseExpiry(*expires)
+	if err != nil {
+		return err
+	}
+
+	account, key, err := store.Create(*name, splitList(*projects), splitList(*scopes), expiresAt)
+	if err != nil {
+		return err
+	}
+
+	printServiceAccountKey(out, account, key)
+	return nil
+}
+
+func rotateServiceAccount(args []string, store serviceAccountStore, out io.Writer) error {
+	flags := flag.NewFlagSet("service-account rotate", flag.ContinueOnError)
+	name := flags.String("name", "", "service account name")
+	expires := flags.String("expires", "", "optional new key lifetime, keeps the current expiry when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("--name is required")
+	}
+	expiresAt, err := parseExpiry(*expires)
+	if err != nil {
+		return err
+	}
+
+	account, key, err := store.Rotate(*name, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	printServiceAccountKey(out, account, key)
+	return nil
+}
+
+func listServiceAccounts(store serviceAccountStore, out io.Writer) error {
+	accounts, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		fmt.Fprintf(out, "%s\t%s…\tprojects=%s\tscopes=%s\tlast_used=%s\texpires=%s\n",
+			account.Name, account.KeyPrefix,
+			strings.Join(account.Projects, ","), strings.Join(account.Scopes, ","),
+			formatOptionalTime(account.LastUsedAt, "never"), formatOptionalTime(account.ExpiresAt, "never"))
+	}
+
+	return nil
+}
+
+func printServiceAccountKey(out io.Writer, account *models.ServiceAccount, key string) {
+	fmt.Fprintf(out, "Service account: %s\n", account.Name)
+	fmt.Fprintf(out, "Expires: %s\n", formatOptionalTime(account.ExpiresAt, "never"))
+	fmt.Fprintf(out, "API key (shown only once): \n%s\n", key)
+}
+
+// parseExpiry 将有效期转换为过期时间，空字符串表示永不过期
+func parseExpiry(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	lifetime, err := parseTTL(value)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(lifetime)
+	return &expiresAt, nil
+}
+
+func formatOptionalTime(value *time.Time, fallback string) string {
+	if value == nil {
+		return fallback
+	}
+	return value.UTC().Format(time.RFC3339)
+}
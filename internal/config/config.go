@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +17,11 @@ type Config struct {
 	JWT      JWTConfig
 	Webhook  WebhookConfig
 	GitHub   GitHubConfig
+	Stats    StatsConfig
+	Download DownloadConfig
+	TLS      TLSConfig
+	Cache    CacheConfig
+	Commit   CommitConfig
 }
 
 type DatabaseConfig struct {
@@ -22,10 +30,29 @@ type DatabaseConfig struct {
 
 type JWTConfig struct {
 	PublicKey  string
+	JWKSPath   string
 	PrivateKey string
 	Issuer     string
 	Subject    string
 	Algorithm  string
+	// AllowUnscopedTokens 允许不带 projects/scopes claims 的旧 token 访问所有项目
+	AllowUnscopedTokens bool
+	// AllowTokensWithoutJTI 允许不带 jti 的旧 token，这些 token 无法吊销
+	AllowTokensWithoutJTI bool
+	// RevocationRefreshInterval 从数据库刷新已吊销 jti 缓存的间隔
+	RevocationRefreshInterval time.Duration
+	GitHubOIDC                GitHubOIDCConfig
+}
+
+// GitHubOIDCConfig GitHub Actions OIDC token 的验证配置
+type GitHubOIDCConfig struct {
+	Enabled bool
+	Issuer  string
+	// JWKS 可以是 https 地址或本地文件路径
+	JWKS     string
+	Audience string
+	// Scopes 授予 OIDC token 的操作，仅对 repository 匹配的项目生效
+	Scopes []string
 }
 
 type WebhookConfig struct {
@@ -33,6 +60,53 @@ type WebhookConfig struct {
 	GitHubToken    string
 }
 
+type GitHubConfig struct {
+	Token string
+}
+
+type DownloadConfig struct {
+	SigningSecret string
+	SignedURLTTL  time.Duration
+	SignedURLMax  time.Duration
+	ProxyProjects []string
+	ProxySources  []string
+	CacheDir      string
+	CacheMaxSize  int64
+}
+
+// TLSConfig 由服务端直接终止 TLS 时的配置，CertFile 为空时使用明文 HTTP
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile 设置后请求客户端证书，并用于验证证书链
+	ClientCAFile string
+	// RequireClientCert 为 true 时认证接口只接受客户端证书
+	RequireClientCert bool
+	// ClientMapFile 证书 subject/SAN 到项目与权限的映射文件
+	ClientMapFile string
+}
+
+type CacheConfig struct {
+	// Control 查询接口响应的 Cache-Control 头
+	Control string
+	// TTL 与 MaxSize 为进程内响应缓存的有效期与容量上限，任一为 0 时禁用
+	TTL     time.Duration
+	MaxSize int64
+	// NotifyChannel 跨实例广播缓存失效的 Postgres NOTIFY 频道，为空时不广播（环境变量设为 none）
+	NotifyChannel string
+}
+
+// CommitConfig 提交构建时的校验规则
+type CommitConfig struct {
+	// EOLPolicy 向已停止支持（eol）的版本提交构建时的处理方式：reject 拒绝，warn 仅在响应中警告
+	EOLPolicy string
+}
+
+type StatsConfig struct {
+	FlushInterval time.Duration
+	BatchSize     int
+}
+
 func Load() (*Config, error) {
 	// 加载 .env 文件
 	_ = godotenv.Load()
@@ -50,21 +124,113 @@ func Load() (*Config, error) {
 			URL: getEnvRequired("DB_URL"),
 		},
 		LogLevel: getEnvDefault("LOG_LEVEL", "info"),
-		JWT: JWTConfig{
-			PublicKey:  getEnvRequired("API_PUBLIC_KEY"),
-			PrivateKey: getEnvRequired("API_PRIVATE_KEY"),
-			Issuer:     getEnvDefault("API_ISSUER", "MenthaMC"),
-			Subject:    getEnvDefault("API_SUBJECT", "leaves-ci"),
-			Algorithm:  getEnvDefault("API_ALGO", "ES256"),
-		},
+		JWT:      loadJWT(),
 		Webhook: WebhookConfig{
 			CommitBuildURL: os.Getenv("COMMIT_BUILD_WEBHOOK_URL"),
 		},
+		GitHub: GitHubConfig{
+			Token: os.Getenv("GITHUB_TOKEN"),
+		},
+		Stats: StatsConfig{
+			FlushInterval: getEnvDuration("DOWNLOAD_STATS_FLUSH_INTERVAL", 10*time.Second),
+			BatchSize:     getEnvInt("DOWNLOAD_STATS_BATCH_SIZE", 500),
+		},
+		Download: DownloadConfig{
+			SigningSecret: os.Getenv("DOWNLOAD_SIGNING_SECRET"),
+			SignedURLTTL:  getEnvDuration("DOWNLOAD_SIGNED_URL_TTL", time.Hour),
+			SignedURLMax:  getEnvDuration("DOWNLOAD_SIGNED_URL_MAX_TTL", 7*24*time.Hour),
+			ProxyProjects: getEnvList("DOWNLOAD_PROXY_PROJECTS"),
+			ProxySources:  getEnvList("DOWNLOAD_PROXY_SOURCES"),
+			CacheDir:      getEnvDefault("DOWNLOAD_CACHE_DIR", "cache/downloads"),
+			CacheMaxSize:  int64(getEnvInt("DOWNLOAD_CACHE_MAX_MB", 2048)) << 20,
+		},
+		TLS: TLSConfig{
+			CertFile:          os.Getenv("TLS_CERT_FILE"),
+			KeyFile:           os.Getenv("TLS_KEY_FILE"),
+			ClientCAFile:      os.Getenv("TLS_CLIENT_CA_FILE"),
+			RequireClientCert: getEnvBool("TLS_REQUIRE_CLIENT_CERT", true),
+			ClientMapFile:     os.Getenv("TLS_CLIENT_MAP_FILE"),
+		},
+		Cache: CacheConfig{
+			Control:       getEnvDefault("HTTP_CACHE_CONTROL", "public, no-cache"),
+			TTL:           getEnvDuration("RESPONSE_CACHE_TTL", 5*time.Minute),
+			MaxSize:       int64(getEnvInt("RESPONSE_CACHE_MAX_MB", 64)) << 20,
+			NotifyChannel: loadNotifyChannel(),
+		},
+		Commit: CommitConfig{
+			EOLPolicy: getEnvDefault("COMMIT_EOL_POLICY", "reject"),
+		},
+	}
+
+	if config.Commit.EOLPolicy != "reject" && config.Commit.EOLPolicy != "warn" {
+		return nil, fmt.Errorf("COMMIT_EOL_POLICY must be reject or warn")
+	}
+
+	if config.TLS.ClientCAFile != "" && (config.TLS.CertFile == "" || config.TLS.KeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set when TLS_CLIENT_CA_FILE is set")
+	}
+	if config.TLS.ClientCAFile != "" && config.TLS.ClientMapFile == "" {
+		return nil, fmt.Errorf("TLS_CLIENT_MAP_FILE must be set when TLS_CLIENT_CA_FILE is set")
+	}
+
+	if config.JWT.PublicKey == "" && config.JWT.JWKSPath == "" {
+		return nil, fmt.Errorf("either API_PUBLIC_KEY or API_JWKS_PATH must be set")
 	}
 
 	return config, nil
 }
 
+// LoadJWT 只加载 JWT 相关配置，供不需要数据库的命令行子命令使用
+func LoadJWT() JWTConfig {
+	// 加载 .env 文件
+	_ = godotenv.Load()
+
+	return loadJWT()
+}
+
+func loadJWT() JWTConfig {
+	return JWTConfig{
+		PublicKey:  os.Getenv("API_PUBLIC_KEY"),
+		JWKSPath:   os.Getenv("API_JWKS_PATH"),
+		PrivateKey: os.Getenv("API_PRIVATE_KEY"),
+		Issuer:     getEnvDefault("API_ISSUER", "MenthaMC"),
+		Subject:    getEnvDefault("API_SUBJECT", "leaves-ci"),
+		Algorithm:  getEnvDefault("API_ALGO", "ES256"),
+
+		// 兼容已发放的旧 token，下一个版本起默认改为 false
+		AllowUnscopedTokens:       getEnvBool("API_ALLOW_UNSCOPED_TOKENS", true),
+		AllowTokensWithoutJTI:     getEnvBool("API_ALLOW_TOKENS_WITHOUT_JTI", true),
+		RevocationRefreshInterval: getEnvDuration("API_REVOCATION_REFRESH_INTERVAL", 30*time.Second),
+		GitHubOIDC:                loadGitHubOIDC(),
+	}
+}
+
+func loadGitHubOIDC() GitHubOIDCConfig {
+	issuer := getEnvDefault("GITHUB_OIDC_ISSUER", "https://token.actions.githubusercontent.com")
+
+	scopes := getEnvList("GITHUB_OIDC_SCOPES")
+	if len(scopes) == 0 {
+		scopes = []string{"commit_build", "manage_download_sources"}
+	}
+
+	return GitHubOIDCConfig{
+		Enabled:  getEnvBool("GITHUB_OIDC_ENABLED", false),
+		Issuer:   issuer,
+		JWKS:     getEnvDefault("GITHUB_OIDC_JWKS", strings.TrimSuffix(issuer, "/")+"/.well-known/jwks"),
+		Audience: os.Getenv("GITHUB_OIDC_AUDIENCE"),
+		Scopes:   scopes,
+	}
+}
+
+// loadNotifyChannel CACHE_NOTIFY_CHANNEL 设为 none 时禁用跨实例广播
+func loadNotifyChannel() string {
+	channel := getEnvDefault("CACHE_NOTIFY_CHANNEL", "webapi_changes")
+	if channel == "none" {
+		return ""
+	}
+	return channel
+}
+
 func getEnvRequired(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -79,3 +245,41 @@ func getEnvDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList 读取逗号分隔的列表，忽略空白项
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -10,7 +10,7 @@ import (
 	_ "github.com/lib/pq"
 )
 
-const currentDBVersion = 1
+const currentDBVersion = 13
 
 var db *sql.DB
 
@@ -79,9 +79,40 @@ func executeInitScript() error {
 
 func migrateDatabase(from, to int) error {
 	logger.Infof("Migrating database from version %d to %d", from, to)
-	
-	// 这里可以添加迁移逻辑
-	// 目前没有迁移脚本，所以直接返回
-	
+
+	for version := from + 1; version <= to; version++ {
+		if err := executeMigration(version); err != nil {
+			return fmt.Errorf("failed to migrate database to version %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func executeMigration(version int) error {
+	migrationSQL, err := ioutil.ReadFile(filepath.Join("sql", "migrations", fmt.Sprintf("%d.sql", version)))
+	if err != nil {
+		return fmt.Errorf("failed to read migration script: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(string(migrationSQL)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE general SET version = $1", version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Infof("Database migrated to version %d", version)
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,618 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"webapi/internal/logger"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	projectIDPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	repoPattern        = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	versionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.+ -]{0,63}$`)
+)
+
+func (h *Handlers) CreateProject(c *gin.Context) {
+	var req models.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !h.requireScope(c, utils.ScopeAdmin, globalProject) {
+		return
+	}
+
+	project := models.Project{
+		ID:               req.ID,
+		Name:             req.Name,
+		Repo:             req.Repo,
+		VersionScheme:    req.VersionScheme,
+		AutoProvision:    req.AutoProvision,
+		VersionGroupRule: req.VersionGroupRule,
+	}
+	if project.VersionScheme == "" {
+		project.VersionScheme = utils.VersionSchemeMinecraft
+	}
+	if project.VersionGroupRule == "" {
+		project.VersionGroupRule = utils.DefaultVersionGroupRule
+	}
+	if err := validateProject(project); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	existing, err := h.services.Project.GetByID(project.ID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if existing != nil {
+		utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Project %s already exists", project.ID))
+		return
+	}
+
+	if err := h.services.Project.Create(project); err != nil {
+		logger.Errorf("Failed to create project %s: %v", project.ID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: project.ID})
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"project": adminProject(project),
+	})
+}
+
+func (h *Handlers) UpdateProject(c *gin.Context) {
+	projectID := c.Param("project")
+
+	var req models.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !h.requireScope(c, utils.ScopeAdmin, projectID) {
+		return
+	}
+
+	project, err := h.services.Project.GetByID(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if project == nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	if req.Name != nil {
+		project.Name = *req.Name
+	}
+	if req.Repo != nil {
+		project.Repo = *req.Repo
+	}
+	if req.VersionScheme != nil {
+		project.VersionScheme = *req.VersionScheme
+	}
+	if req.AutoProvision != nil {
+		project.AutoProvision = *req.AutoProvision
+	}
+	if req.VersionGroupRule != nil {
+		project.VersionGroupRule = *req.VersionGroupRule
+	}
+	if err := validateProject(*project); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if err := h.services.Project.Update(*project); err != nil {
+		logger.Errorf("Failed to update project %s: %v", projectID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: projectID})
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"project": adminProject(*project),
+	})
+}
+
+// DeleteProject 只能删除没有构建的项目，其版本组、版本与下载源一并删除
+func (h *Handlers) DeleteProject(c *gin.Context) {
+	projectID := c.Param("project")
+
+	if !h.requireScope(c, utils.ScopeAdmin, globalProject) {
+		return
+	}
+
+	project, err := h.services.Project.GetByID(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if project == nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	builds, err := h.services.Project.CountBuilds(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if builds > 0 {
+		utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Project %s still has %d builds", projectID, builds))
+		return
+	}
+
+	if err := h.services.Project.Delete(projectID); err != nil {
+		logger.Errorf("Failed to delete project %s: %v", projectID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: projectID})
+
+	utils.SuccessResponse(c, nil)
+}
+
+func (h *Handlers) CreateVersionGroup(c *gin.Context) {
+	projectID := c.Param("project")
+
+	var req models.VersionGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !h.requireScope(c, utils.ScopeManageVersions, projectID) {
+		return
+	}
+
+	if !h.requireProject(c, projectID) {
+		return
+	}
+	if err := validateVersionName(req.Name); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	existing, err := h.services.VersionGroup.GetVersionGroupID(projectID, req.Name)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if existing != 0 {
+		utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Version group %s already exists", req.Name))
+		return
+	}
+
+	versionGroupID, err := h.services.VersionGroup.Create(projectID, req.Name)
+	if err != nil {
+		logger.Errorf("Failed to create version group %s of %s: %v", req.Name, projectID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: projectID})
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"version_group": models.VersionGroup{ID: versionGroupID, Project: projectID, Name: req.Name},
+	})
+}
+
+// UpdateVersionGroup 重命名版本组
+func (h *Handlers) UpdateVersionGroup(c *gin.Context) {
+	projectID := c.Param("project")
+	family := c.Param("family")
+
+	var req models.VersionGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !h.requireScope(c, utils.ScopeManageVersions, projectID) {
+		return
+	}
+
+	versionGroupID, ok := h.requireVersionGroup(c, projectID, family)
+	if !ok {
+		return
+	}
+	if err := validateVersionName(req.Name); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if req.Name != family {
+		existing, err := h.services.VersionGroup.GetVersionGroupID(projectID, req.Name)
+		if err != nil {
+			utils.InternalServerErrorResponse(c)
+			return
+		}
+		if existing != 0 {
+			utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Version group %s already exists", req.Name))
+			return
+		}
+	}
+
+	if err := h.services.VersionGroup.Rename(versionGroupID, req.Name); err != nil {
+		logger.Errorf("Failed to rename version group %s of %s: %v", family, projectID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: projectID})
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"version_group": models.VersionGroup{ID: versionGroupID, Project: projectID, Name: req.Name},
+	})
+}
+
+// DeleteVersionGroup 只能删除不含任何版本的版本组
+func (h *Handlers) DeleteVersionGroup(c *gin.Context) {
+	projectID := c.Param("project")
+	family := c.Param("family")
+
+	if !h.requireScope(c, utils.ScopeManageVersions, projectID) {
+		return
+	}
+
+	versionGroupID, ok := h.requireVersionGroup(c, projectID, family)
+	if !ok {
+		return
+	}
+
+	versionIDs, _, err := h.services.VersionGroup.GetVersionsByGroupID(projectID, versionGroupID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if len(versionIDs) > 0 {
+		utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Version group %s still has %d versions", family, len(versionIDs)))
+		return
+	}
+
+	if err := h.services.VersionGroup.Delete(versionGroupID); err != nil {
+		logger.Errorf("Failed to delete version group %s of %s: %v", family, projectID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: projectID})
+
+	utils.SuccessResponse(c, nil)
+}
+
+func (h *Handlers) CreateVersion(c *gin.Context) {
+	projectID := c.Param("project")
+
+	var req models.VersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+	if req.Name == nil || req.VersionGroup == nil {
+		utils.BadRequestResponse(c, "name and version_group are required")
+		return
+	}
+
+	if !h.requireScope(c, utils.ScopeManageVersions, projectID) {
+		return
+	}
+
+	if !h.requireProject(c, projectID) {
+		return
+	}
+
+	version := models.Version{Project: projectID, VersionMetadata: models.VersionMetadata{Status: models.VersionStatusSupported}}
+	if err := applyVersionRequest(&version, req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	existing, err := h.services.Version.GetVersion(projectID, version.Name)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if existing != nil {
+		utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Version %s already exists", version.Name))
+		return
+	}
+
+	versionGroupID, err := h.services.VersionGroup.GetVersionGroupID(projectID, *req.VersionGroup)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if versionGroupID == 0 {
+		utils.BadRequestResponse(c, fmt.Sprintf("Version group %s not found", *req.VersionGroup))
+		return
+	}
+	version.VersionGroup = versionGroupID
+
+	version.ID, err = h.services.Version.Create(version)
+	if err != nil {
+		logger.Errorf("Failed to create version %s of %s: %v", version.Name, projectID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: projectID, Version: version.Name})
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"version": version,
+	})
+}
+
+// UpdateVersion 修改版本名称与元数据，给出 version_group 时将版本移入该版本组
+func (h *Handlers) UpdateVersion(c *gin.Context) {
+	projectID := c.Param("project")
+	versionName := c.Param("version")
+
+	var req models.VersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !h.requireScope(c, utils.ScopeManageVersions, projectID) {
+		return
+	}
+
+	version, err := h.services.Version.GetVersion(projectID, versionName)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if version == nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	if err := applyVersionRequest(version, req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if version.Name != versionName {
+		existing, err := h.services.Version.GetVersion(projectID, version.Name)
+		if err != nil {
+			utils.InternalServerErrorResponse(c)
+			return
+		}
+		if existing != nil {
+			utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Version %s already exists", version.Name))
+			return
+		}
+	}
+
+	if req.VersionGroup != nil {
+		versionGroupID, err := h.services.VersionGroup.GetVersionGroupID(projectID, *req.VersionGroup)
+		if err != nil {
+			utils.InternalServerErrorResponse(c)
+			return
+		}
+		if versionGroupID == 0 {
+			utils.BadRequestResponse(c, fmt.Sprintf("Version group %s not found", *req.VersionGroup))
+			return
+		}
+
+		if versionGroupID != version.VersionGroup {
+			conflicts, err := h.services.Version.MoveConflicts(version.ID, versionGroupID)
+			if err != nil {
+				utils.InternalServerErrorResponse(c)
+				return
+			}
+			if len(conflicts) > 0 {
+				utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Builds %s of %s already exist in version group %s",
+					joinInts(conflicts), versionName, *req.VersionGroup))
+				return
+			}
+			version.VersionGroup = versionGroupID
+		}
+	}
+
+	if err := h.services.Version.Update(*version); err != nil {
+		logger.Errorf("Failed to update version %s of %s: %v", versionName, projectID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: projectID, Version: version.Name})
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"version": version,
+	})
+}
+
+// DeleteVersion 只能删除没有构建的版本
+func (h *Handlers) DeleteVersion(c *gin.Context) {
+	projectID := c.Param("project")
+	versionName := c.Param("version")
+
+	if !h.requireScope(c, utils.ScopeManageVersions, projectID) {
+		return
+	}
+
+	version, err := h.services.Version.GetVersion(projectID, versionName)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if version == nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	builds, err := h.services.Version.CountBuilds(version.ID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if builds > 0 {
+		utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("Version %s still has %d builds", versionName, builds))
+		return
+	}
+
+	if err := h.services.Version.Delete(version.ID); err != nil {
+		logger.Errorf("Failed to delete version %s of %s: %v", versionName, projectID, err)
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.markProjectModified(models.ChangeEvent{Project: projectID, Version: versionName})
+
+	utils.SuccessResponse(c, nil)
+}
+
+// requireProject 项目不存在时写入 404 响应并返回 false
+func (h *Handlers) requireProject(c *gin.Context, projectID string) bool {
+	project, err := h.services.Project.GetByID(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return false
+	}
+	if project == nil {
+		utils.NotFoundResponse(c)
+		return false
+	}
+	return true
+}
+
+// requireVersionGroup 查找版本组 ID，不存在时写入 404 响应并返回 false
+func (h *Handlers) requireVersionGroup(c *gin.Context, projectID, family string) (int, bool) {
+	versionGroupID, err := h.services.VersionGroup.GetVersionGroupID(projectID, family)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return 0, false
+	}
+	if versionGroupID == 0 {
+		utils.NotFoundResponse(c)
+		return 0, false
+	}
+	return versionGroupID, true
+}
+
+// adminProject 转换为包含全部设置的管理接口响应
+func adminProject(project models.Project) models.AdminProject {
+	return models.AdminProject{
+		ID:               project.ID,
+		Name:             project.Name,
+		Repo:             project.Repo,
+		VersionScheme:    project.VersionScheme,
+		AutoProvision:    project.AutoProvision,
+		VersionGroupRule: project.VersionGroupRule,
+	}
+}
+
+func validateProject(project models.Project) error {
+	if !projectIDPattern.MatchString(project.ID) {
+		return fmt.Errorf("id must be 1-64 lowercase letters, digits, '-' or '_'")
+	}
+	if strings.TrimSpace(project.Name) == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	if project.Repo != "" && !repoPattern.MatchString(project.Repo) {
+		return fmt.Errorf("repo must be in owner/name form")
+	}
+	if !utils.IsVersionScheme(project.VersionScheme) {
+		return fmt.Errorf("version_scheme must be minecraft, semver or calver")
+	}
+	if _, err := regexp.Compile(project.VersionGroupRule); err != nil || project.VersionGroupRule == "" {
+		return fmt.Errorf("version_group_rule must be a valid regular expression")
+	}
+	return nil
+}
+
+// validateVersionName 校验版本与版本组名称，latest 保留给查询接口表示最新版本
+func validateVersionName(name string) error {
+	if !versionNamePattern.MatchString(name) || strings.HasSuffix(name, " ") {
+		return fmt.Errorf("name %q must be 1-64 letters, digits, spaces or '.', '_', '+', '-'", name)
+	}
+	if name == "latest" {
+		return fmt.Errorf("name latest is reserved")
+	}
+	return nil
+}
+
+// applyVersionRequest 将请求中给出的名称与元数据写入 version 并校验，不处理 version_group
+func applyVersionRequest(version *models.Version, req models.VersionRequest) error {
+	if req.Name != nil {
+		if err := validateVersionName(*req.Name); err != nil {
+			return err
+		}
+		version.Name = *req.Name
+	}
+
+	if req.ReleaseDate != nil {
+		if *req.ReleaseDate == "" {
+			version.ReleaseDate = nil
+		} else {
+			if _, err := time.Parse("2006-01-02", *req.ReleaseDate); err != nil {
+				return fmt.Errorf("release_date must be in YYYY-MM-DD form")
+			}
+			date := *req.ReleaseDate
+			version.ReleaseDate = &date
+		}
+	}
+
+	if req.Status != nil {
+		switch *req.Status {
+		case models.VersionStatusSupported, models.VersionStatusDeprecated, models.VersionStatusEOL:
+			version.Status = *req.Status
+		default:
+			return fmt.Errorf("status must be supported, deprecated or eol")
+		}
+	}
+
+	if req.JavaMinimum != nil {
+		version.Java.Minimum = optionalJavaVersion(*req.JavaMinimum)
+	}
+	if req.JavaRecommended != nil {
+		version.Java.Recommended = optionalJavaVersion(*req.JavaRecommended)
+	}
+	for _, java := range []*int{version.Java.Minimum, version.Java.Recommended} {
+		if java != nil && *java < 0 {
+			return fmt.Errorf("java versions must not be negative")
+		}
+	}
+	if version.Java.Minimum != nil && version.Java.Recommended != nil && *version.Java.Recommended < *version.Java.Minimum {
+		return fmt.Errorf("java_recommended must not be lower than java_minimum")
+	}
+
+	if req.Notice != nil {
+		version.Notice = *req.Notice
+	}
+
+	return nil
+}
+
+// optionalJavaVersion 0 表示清除
+func optionalJavaVersion(value int) *int {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, ", ")
+}
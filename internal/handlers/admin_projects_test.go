@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"webapi/internal/models"
+)
+
+func TestValidateNames(t *testing.T) {
+	valid := models.Project{ID: "mint", Name: "Mint", Repo: "MenthaMC/Mint", VersionScheme: "minecraft", VersionGroupRule: `^(\d+\.\d+)`}
+	if err := validateProject(valid); err != nil {
+		t.Errorf("expected %+v to be valid: %v", valid, err)
+	}
+	for _, project := range []models.Project{
+		{ID: "Mint", Name: "Mint", VersionScheme: "minecraft", VersionGroupRule: valid.VersionGroupRule},
+		{ID: "mint/leaves", Name: "Mint", VersionScheme: "minecraft", VersionGroupRule: valid.VersionGroupRule},
+		{ID: "mint", Name: " ", VersionScheme: "minecraft", VersionGroupRule: valid.VersionGroupRule},
+		{ID: "mint", Name: "Mint", Repo: "https://github.com/MenthaMC/Mint", VersionScheme: "minecraft", VersionGroupRule: valid.VersionGroupRule},
+		{ID: "mint", Name: "Mint", VersionScheme: "alphabetical", VersionGroupRule: valid.VersionGroupRule},
+		{ID: "mint", Name: "Mint", VersionScheme: "minecraft", VersionGroupRule: `^(\d+`},
+	} {
+		if err := validateProject(project); err == nil {
+			t.Errorf("expected %+v to be rejected", project)
+		}
+	}
+
+	for _, name := range []string{"1.21.4", "24w14a", "1.14 Pre-Release 2", "2024.10.0-beta+1"} {
+		if err := validateVersionName(name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "latest", "1.21/..", " 1.21", "1.21 ", "-1"} {
+		if err := validateVersionName(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestApplyVersionRequest(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+
+	version := models.Version{Name: "1.21.4", VersionMetadata: models.VersionMetadata{Status: models.VersionStatusSupported}}
+	err := applyVersionRequest(&version, models.VersionRequest{
+		ReleaseDate:     str("2024-12-03"),
+		Status:          str(models.VersionStatusDeprecated),
+		JavaMinimum:     num(21),
+		JavaRecommended: num(21),
+		Notice:          str("Please update to 1.21.5"),
+	})
+	if err != nil {
+		t.Fatalf("applyVersionRequest: %v", err)
+	}
+	if version.Name != "1.21.4" || *version.ReleaseDate != "2024-12-03" || version.Status != models.VersionStatusDeprecated ||
+		*version.Java.Minimum != 21 || *version.Java.Recommended != 21 || version.Notice == "" {
+		t.Errorf("unexpected version %+v", version)
+	}
+
+	// 空字符串与 0 清除已有的值
+	if err := applyVersionRequest(&version, models.VersionRequest{ReleaseDate: str(""), JavaMinimum: num(0)}); err != nil {
+		t.Fatalf("applyVersionRequest: %v", err)
+	}
+	if version.ReleaseDate != nil || version.Java.Minimum != nil || *version.Java.Recommended != 21 {
+		t.Errorf("expected release date and minimum Java to be cleared, got %+v", version)
+	}
+
+	for _, req := range []models.VersionRequest{
+		{Name: str("latest")},
+		{ReleaseDate: str("03/12/2024")},
+		{Status: str("archived")},
+		{JavaMinimum: num(25), JavaRecommended: num(21)},
+		{JavaMinimum: num(-1)},
+	} {
+		candidate := version
+		if err := applyVersionRequest(&candidate, req); err == nil {
+			t.Errorf("expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestAdminProjectSettingsArePrivate(t *testing.T) {
+	project := models.Project{ID: "mint", Name: "Mint", Repo: "MenthaMC/Mint", VersionScheme: "semver", VersionGroupRule: `^(\d+)`}
+
+	public, _ := json.Marshal(project)
+	admin, _ := json.Marshal(adminProject(project))
+	for _, field := range []string{"version_scheme", "auto_provision", "version_group_rule"} {
+		if strings.Contains(string(public), field) {
+			t.Errorf("expected the public project to hide %s, got %s", field, public)
+		}
+		if !strings.Contains(string(admin), `"`+field+`"`) {
+			t.Errorf("expected the admin project to include %s, got %s", field, admin)
+		}
+	}
+}
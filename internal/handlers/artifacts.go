@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetArtifact(c *gin.Context) {
+	project, build, versionName, ok := h.resolveArtifact(c)
+	if !ok {
+		return
+	}
+
+	buildResponse := utils.BuildToBuildResponse(*build, nil)
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"project_id":   project.ID,
+		"project_name": project.Name,
+		"version":      versionName,
+		"build":        buildResponse.Build,
+		"time":         buildResponse.Time,
+		"channel":      buildResponse.Channel,
+		"sha256":       build.SHA256,
+		"downloads":    buildResponse.Downloads,
+	})
+}
+
+func (h *Handlers) DownloadArtifact(c *gin.Context) {
+	_, build, versionName, ok := h.resolveArtifact(c)
+	if !ok {
+		return
+	}
+
+	downloadSource := c.Query("source")
+	if downloadSource == "" {
+		downloadSource = defaultDownloadSource(build)
+	}
+	if downloadSource == "" {
+		utils.NotFoundResponse(c, "No download source available for this artifact")
+		return
+	}
+
+	downloadURL, err := h.services.Download.GetDownloadURL(downloadSource, build.Project, build.Tag)
+	if err != nil {
+		utils.NotFoundResponse(c, fmt.Sprintf("Download source %s not found for this artifact", downloadSource))
+		return
+	}
+
+	h.deliverDownload(c, build, versionName, downloadSource, downloadURL)
+}
+
+// resolveArtifact 根据路径中的 sha256 查找构建，失败时已写入响应
+func (h *Handlers) resolveArtifact(c *gin.Context) (*models.Project, *models.Build, string, bool) {
+	projectID := c.Param("project")
+	sha256Hex := c.Param("sha256")
+
+	if !utils.IsSHA256Hex(sha256Hex) {
+		utils.BadRequestResponse(c, "Invalid sha256")
+		return nil, nil, "", false
+	}
+
+	project, err := h.services.Project.GetByID(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return nil, nil, "", false
+	}
+	if project == nil {
+		utils.NotFoundResponse(c)
+		return nil, nil, "", false
+	}
+
+	build, err := h.services.Build.GetBuildBySHA256(projectID, sha256Hex)
+	if err != nil {
+		utils.NotFoundResponse(c)
+		return nil, nil, "", false
+	}
+
+	versionName, err := h.services.Version.GetVersionName(build.Version)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return nil, nil, "", false
+	}
+
+	return project, build, versionName, true
+}
+
+// defaultDownloadSource 优先使用 application 下载源，否则取第一个
+func defaultDownloadSource(build *models.Build) string {
+	for _, source := range build.DownloadSources {
+		if source == "application" {
+			return source
+		}
+	}
+	if len(build.DownloadSources) > 0 {
+		return build.DownloadSources[0]
+	}
+	return ""
+}
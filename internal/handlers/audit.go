@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"strconv"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+func (h *Handlers) GetAuditLog(c *gin.Context) {
+	if !h.requireScope(c, utils.ScopeAdmin, globalProject) {
+		return
+	}
+
+	filter := models.AuditFilter{
+		Subject:  c.Query("subject"),
+		JTI:      c.Query("jti"),
+		Endpoint: c.Query("endpoint"),
+		Result:   c.Query("result"),
+		Limit:    defaultAuditLimit,
+	}
+
+	var err error
+	if filter.Since, err = parseTimeParam(c.Query("since")); err != nil {
+		utils.BadRequestResponse(c, "Invalid since")
+		return
+	}
+	if filter.Until, err = parseTimeParam(c.Query("until")); err != nil {
+		utils.BadRequestResponse(c, "Invalid until")
+		return
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			utils.BadRequestResponse(c, "limit must be between 1 and 1000")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.services.Audit.Query(filter)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"entries": entries,
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultBuildListLimit = 100
+	maxBuildListLimit     = 1000
+)
+
+// buildListParams 构建列表支持的查询参数，任意一个出现时启用分页并在响应中返回 next
+var buildListParams = []string{"limit", "cursor", "order", "since", "until", "channel", "from_build", "to_build"}
+
+// parseBuildListFilter 解析构建列表的分页与过滤参数，没有任何参数时返回 paginated=false 以保持原有响应
+func parseBuildListFilter(c *gin.Context) (filter models.BuildListFilter, paginated bool, err error) {
+	for _, param := range buildListParams {
+		if _, ok := c.GetQuery(param); ok {
+			paginated = true
+			break
+		}
+	}
+	if !paginated {
+		return filter, false, nil
+	}
+
+	filter.Limit = defaultBuildListLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxBuildListLimit {
+			return filter, true, fmt.Errorf("limit must be between 1 and %d", maxBuildListLimit)
+		}
+		filter.Limit = limit
+	}
+
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return filter, true, fmt.Errorf("order must be asc or desc")
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		if filter.After, err = utils.DecodeBuildCursor(cursor); err != nil {
+			return filter, true, err
+		}
+	}
+
+	if filter.Since, err = parseTimeParam(c.Query("since")); err != nil {
+		return filter, true, fmt.Errorf("invalid since")
+	}
+	if filter.Until, err = parseTimeParam(c.Query("until")); err != nil {
+		return filter, true, fmt.Errorf("invalid until")
+	}
+
+	filter.Channel = c.Query("channel")
+	if filter.Channel != "" && filter.Channel != "default" && filter.Channel != "experimental" {
+		return filter, true, fmt.Errorf("channel must be default or experimental")
+	}
+
+	if filter.MinBuild, err = parseBuildNumberParam(c, "from_build"); err != nil {
+		return filter, true, err
+	}
+	if filter.MaxBuild, err = parseBuildNumberParam(c, "to_build"); err != nil {
+		return filter, true, err
+	}
+
+	return filter, true, nil
+}
+
+func parseBuildNumberParam(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive build number", name)
+	}
+	return n, nil
+}
+
+// nextBuildCursor 返回响应中的 next 字段，没有下一页时为 null
+func nextBuildCursor(next *models.BuildCursor) interface{} {
+	if next == nil {
+		return nil
+	}
+	return utils.EncodeBuildCursor(*next)
+}
+
+// buildResponses 将构建列表转换为响应，所有构建的变更通过一次查询加载
+func (h *Handlers) buildResponses(builds []models.Build) ([]interface{}, error) {
+	var rowIDs []int
+	for _, build := range builds {
+		if len(build.Changes) > 0 {
+			rowIDs = append(rowIDs, build.ID)
+		}
+	}
+
+	changes, err := h.services.Change.GetChangesByBuilds(rowIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []interface{}
+	for _, build := range builds {
+		buildChanges := changes[build.ID]
+		if len(build.Changes) == 0 {
+			buildChanges = []models.ChangeResponse{}
+		}
+		responses = append(responses, utils.BuildToBuildResponse(build, buildChanges))
+	}
+
+	return responses, nil
+}
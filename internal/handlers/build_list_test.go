@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseBuildListFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	parse := func(query string) (models.BuildListFilter, bool, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/v2/projects/mint/versions/1.21/builds"+query, nil)
+		return parseBuildListFilter(c)
+	}
+
+	if _, paginated, err := parse(""); err != nil || paginated {
+		t.Errorf("expected no parameters to keep the unpaginated response, got paginated=%v err=%v", paginated, err)
+	}
+
+	cursor := utils.EncodeBuildCursor(models.BuildCursor{BuildID: 40, ID: 812})
+	filter, paginated, err := parse("?limit=20&order=desc&channel=experimental&from_build=10&to_build=50&since=2024-06-01&cursor=" + cursor)
+	if err != nil || !paginated {
+		t.Fatalf("unexpected result paginated=%v err=%v", paginated, err)
+	}
+	if filter.Limit != 20 || !filter.Descending || filter.Channel != "experimental" ||
+		filter.MinBuild != 10 || filter.MaxBuild != 50 || filter.Since.IsZero() ||
+		filter.After == nil || filter.After.BuildID != 40 {
+		t.Errorf("unexpected filter %+v", filter)
+	}
+
+	if filter, _, _ := parse("?order=asc"); filter.Limit != defaultBuildListLimit {
+		t.Errorf("expected default limit %d, got %d", defaultBuildListLimit, filter.Limit)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=5000", "?order=up", "?channel=beta", "?cursor=bogus", "?from_build=-1", "?since=yesterday"} {
+		if _, _, err := parse(query); err == nil {
+			t.Errorf("expected %s to be rejected", query)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCacheStats 返回本实例响应缓存的命中统计
+func (h *Handlers) GetCacheStats(c *gin.Context) {
+	if !h.requireScope(c, utils.ScopeAdmin, globalProject) {
+		return
+	}
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"enabled":   h.services.Responses.Enabled(),
+		"responses": h.services.Responses.Stats(),
+	})
+}
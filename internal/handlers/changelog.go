@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxChangelogBuilds 单次变更日志最多包含的构建数
+const maxChangelogBuilds = 1000
+
+// GetVersionChangelog 返回版本所在版本组内两个构建之间的变更，to 默认为该版本的最新构建
+func (h *Handlers) GetVersionChangelog(c *gin.Context) {
+	projectID := c.Param("project")
+
+	project, err := h.services.Project.GetByID(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if project == nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	versionID, versionName, err := h.resolveVersion(projectID, c.Param("version"))
+	if err != nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	versionGroupID, err := h.services.Version.GetVersionGroupID(versionID)
+	if err != nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	versionIDs, versionNames, err := h.services.Version.GetVersionsByGroupID(projectID, versionGroupID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.writeChangelog(c, projectID, []int{versionID}, versionIDs, versionNames, map[string]interface{}{
+		"project_id":   project.ID,
+		"project_name": project.Name,
+		"version":      versionName,
+	})
+}
+
+// GetVersionGroupChangelog 返回版本组内两个构建之间的变更，to 默认为组内最新构建
+func (h *Handlers) GetVersionGroupChangelog(c *gin.Context) {
+	projectID := c.Param("project")
+	family := c.Param("family")
+
+	project, err := h.services.Project.GetByID(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if project == nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	versionGroupID, err := h.services.VersionGroup.GetVersionGroupID(projectID, family)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if versionGroupID == 0 {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	versionIDs, versionNames, err := h.services.VersionGroup.GetVersionsByGroupID(projectID, versionGroupID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	h.writeChangelog(c, projectID, versionIDs, versionIDs, versionNames, map[string]interface{}{
+		"project_id":    project.ID,
+		"project_name":  project.Name,
+		"version_group": family,
+	})
+}
+
+// writeChangelog 解析 from/to 引用并返回 (from, to] 之间的构建与变更
+// latest 在 latestScope 内解析，其他引用在整个版本组内解析
+func (h *Handlers) writeChangelog(c *gin.Context, projectID string, latestScope, versionIDs []int, versionNames []string, response map[string]interface{}) {
+	fromRef := c.Query("from")
+	if fromRef == "" {
+		utils.BadRequestResponse(c, "from is required")
+		return
+	}
+	toRef := c.DefaultQuery("to", "latest")
+
+	resolve := func(ref string) (int, error) {
+		if ref == "latest" {
+			return h.services.Build.ResolveBuildRef(projectID, latestScope, ref)
+		}
+		return h.services.Build.ResolveBuildRef(projectID, versionIDs, ref)
+	}
+
+	from, err := resolve(fromRef)
+	if err != nil {
+		utils.NotFoundResponse(c, err.Error())
+		return
+	}
+	to, err := resolve(toRef)
+	if err != nil {
+		utils.NotFoundResponse(c, err.Error())
+		return
+	}
+	if from > to {
+		from, to = to, from
+	}
+
+	builds, next, err := h.services.Build.ListBuilds(projectID, versionIDs, models.BuildListFilter{
+		MinBuild: from + 1,
+		MaxBuild: to,
+		Limit:    maxChangelogBuilds,
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if next != nil {
+		utils.BadRequestResponse(c, "Changelog range spans more than 1000 builds")
+		return
+	}
+
+	rowIDs := make([]int, len(builds))
+	for i, build := range builds {
+		rowIDs[i] = build.ID
+	}
+	changes, err := h.services.Change.GetChangesByBuilds(rowIDs)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	names := make(map[int]string, len(versionIDs))
+	for i, id := range versionIDs {
+		names[id] = versionNames[i]
+	}
+
+	entries, total := changelogBuilds(builds, changes, names)
+	response["from"] = from
+	response["to"] = to
+	response["total_changes"] = total
+	response["builds"] = entries
+
+	utils.SuccessResponse(c, response)
+}
+
+// changelogBuilds 按构建号顺序组织变更，同一提交只保留在首次出现的构建中
+func changelogBuilds(builds []models.Build, changes map[int][]models.ChangeResponse, versionNames map[int]string) ([]models.ChangelogBuild, int) {
+	seen := make(map[string]bool)
+	entries := make([]models.ChangelogBuild, 0, len(builds))
+	total := 0
+
+	for _, build := range builds {
+		entry := models.ChangelogBuild{
+			Build:   build.BuildID,
+			Version: versionNames[build.Version],
+			Time:    build.Time.Format("2006-01-02T15:04:05.000Z"),
+			Changes: []models.ChangeResponse{},
+		}
+		for _, change := range changes[build.ID] {
+			if seen[change.Commit] {
+				continue
+			}
+			seen[change.Commit] = true
+			entry.Changes = append(entry.Changes, change)
+		}
+		total += len(entry.Changes)
+		entries = append(entries, entry)
+	}
+
+	return entries, total
+}
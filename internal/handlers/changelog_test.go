@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+	"webapi/internal/models"
+)
+
+func TestChangelogBuilds(t *testing.T) {
+	at := time.Date(2024, 12, 3, 10, 0, 0, 0, time.UTC)
+	builds := []models.Build{
+		{ID: 11, BuildID: 5, Version: 1, Time: at},
+		{ID: 14, BuildID: 6, Version: 2, Time: at.Add(time.Hour)},
+		{ID: 15, BuildID: 7, Version: 2, Time: at.Add(2 * time.Hour)},
+	}
+	changes := map[int][]models.ChangeResponse{
+		11: {{Commit: "aaa111", Summary: "Fix chunk loading"}},
+		// 移植到新版本的构建重复包含了 aaa111
+		14: {{Commit: "aaa111", Summary: "Fix chunk loading"}, {Commit: "bbb222", Summary: "Update to 1.21.4"}},
+	}
+
+	entries, total := changelogBuilds(builds, changes, map[int]string{1: "1.21.3", 2: "1.21.4"})
+	if total != 2 || len(entries) != 3 {
+		t.Fatalf("expected 3 builds with 2 changes, got %d builds with %d changes", len(entries), total)
+	}
+	if entries[0].Build != 5 || entries[0].Version != "1.21.3" || len(entries[0].Changes) != 1 {
+		t.Errorf("unexpected first entry %+v", entries[0])
+	}
+	if entries[1].Version != "1.21.4" || len(entries[1].Changes) != 1 || entries[1].Changes[0].Commit != "bbb222" {
+		t.Errorf("expected the duplicated commit to be dropped from build 6, got %+v", entries[1])
+	}
+	if entries[2].Changes == nil || len(entries[2].Changes) != 0 {
+		t.Errorf("expected an empty change list for build 7, got %+v", entries[2].Changes)
+	}
+	if entries[0].Time != "2024-12-03T10:00:00.000Z" {
+		t.Errorf("unexpected time %q", entries[0].Time)
+	}
+}
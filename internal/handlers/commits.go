@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"webapi/internal/logger"
 	"webapi/internal/models"
 	"webapi/internal/utils"
@@ -19,6 +20,10 @@ func (h *Handlers) CommitBuild(c *gin.Context) {
 		return
 	}
 
+	if !h.requireScope(c, utils.ScopeCommitBuild, req.ProjectID) {
+		return
+	}
+
 	// 解析变更数据
 	changesData, err := h.services.Change.ParseChanges(req.Changes)
 	if err != nil {
@@ -26,52 +31,88 @@ func (h *Handlers) CommitBuild(c *gin.Context) {
 		return
 	}
 
-	// 插入变更记录
-	changeIDs, err := h.services.Change.InsertChanges(req.ProjectID, changesData)
+	// 版本不存在时，开启了自动创建的项目按规则得出新版本所属的版本组
+	var warnings []string
+	versionGroup := ""
+	version, err := h.services.Version.GetVersion(req.ProjectID, req.Version)
 	if err != nil {
 		utils.InternalServerErrorResponse(c)
 		return
 	}
-
-	// 获取版本ID
-	versionID, err := h.services.Version.GetVersionID(req.ProjectID, req.Version)
-	if err != nil {
-		utils.BadRequestResponse(c, "Version not found")
-		return
-	}
-
-	// 获取版本组ID
-	versionGroupID, err := h.services.Version.GetVersionGroupID(versionID)
-	if err != nil {
-		utils.InternalServerErrorResponse(c)
-		return
+	if version == nil {
+		project, err := h.services.Project.GetByID(req.ProjectID)
+		if err != nil {
+			utils.InternalServerErrorResponse(c)
+			return
+		}
+		if project == nil || !project.AutoProvision {
+			utils.BadRequestResponse(c, "Version not found")
+			return
+		}
+		if err := validateVersionName(req.Version); err != nil {
+			utils.BadRequestResponse(c, fmt.Sprintf("Version not found and cannot be created: %v", err))
+			return
+		}
+		versionGroup, err = utils.DeriveVersionGroup(project.VersionGroupRule, req.Version)
+		if err == nil {
+			err = validateVersionName(versionGroup)
+		}
+		if err != nil {
+			utils.BadRequestResponse(c, fmt.Sprintf("Version not found and cannot be created: %v", err))
+			return
+		}
+	} else {
+		// 检查版本的支持状态
+		warnings, err = versionLifecycleWarnings(req.Version, version.Status, h.config.Commit.EOLPolicy)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
 	}
 
-	// 获取版本组中的所有版本
-	versionIDs, _, err := h.services.Version.GetVersionsByGroupID(req.ProjectID, versionGroupID)
+	// 在同一事务中创建缺少的版本、插入变更并创建新构建
+	result, err := h.services.Build.Commit(req, changesData, versionGroup)
 	if err != nil {
+		logger.Errorf("Failed to commit build of %s %s: %v", req.ProjectID, req.Version, err)
 		utils.InternalServerErrorResponse(c)
 		return
 	}
-
-	// 获取最新构建ID
-	latestBuildID, err := h.services.Version.GetLatestBuildID(req.ProjectID, versionIDs)
-	if err != nil {
-		utils.InternalServerErrorResponse(c)
-		return
+	if result.Provisioned != nil {
+		logger.Infof("Provisioned version %s in version group %s of %s", result.Provisioned.Version, result.Provisioned.VersionGroup, req.ProjectID)
 	}
 
-	// 创建新构建
-	newBuildID := latestBuildID + 1
-	if err := h.services.Build.CreateBuild(req, versionID, newBuildID, changeIDs); err != nil {
-		utils.InternalServerErrorResponse(c)
-		return
-	}
+	h.markProjectModified(models.ChangeEvent{Project: req.ProjectID, Version: req.Version, Build: result.Build, Tag: req.Tag})
 
 	// 触发 webhook
 	go h.triggerWebhook(req.ProjectID, req.Version, req.Tag)
 
-	utils.SuccessResponse(c, nil)
+	response := map[string]interface{}{
+		"build": result.Build,
+	}
+	if result.Provisioned != nil {
+		response["provisioned"] = result.Provisioned
+	}
+	if len(warnings) > 0 {
+		logger.Warnf("Committed build %d of %s %s: %s", result.Build, req.ProjectID, req.Version, strings.Join(warnings, "; "))
+		response["warnings"] = warnings
+	}
+
+	utils.SuccessResponse(c, response)
+}
+
+// versionLifecycleWarnings 检查能否向该版本提交构建
+// 已停止支持的版本按 policy 拒绝或警告，已弃用的版本只警告
+func versionLifecycleWarnings(version, status, policy string) ([]string, error) {
+	switch status {
+	case models.VersionStatusEOL:
+		if policy == "reject" {
+			return nil, fmt.Errorf("Version %s is end-of-life and no longer accepts builds", version)
+		}
+		return []string{fmt.Sprintf("Version %s is end-of-life", version)}, nil
+	case models.VersionStatusDeprecated:
+		return []string{fmt.Sprintf("Version %s is deprecated", version)}, nil
+	}
+	return nil, nil
 }
 
 func (h *Handlers) CommitDownloadSource(c *gin.Context) {
@@ -81,6 +122,10 @@ func (h *Handlers) CommitDownloadSource(c *gin.Context) {
 		return
 	}
 
+	if !h.requireScope(c, utils.ScopeManageDownloadSources, req.Project) {
+		return
+	}
+
 	// 检查下载源是否存在
 	exists, err := h.services.Download.DownloadSourceExists(req.Project, req.Tag, req.DownloadSource)
 	if err != nil {
@@ -102,6 +147,8 @@ func (h *Handlers) CommitDownloadSource(c *gin.Context) {
 		}
 	}
 
+	h.markProjectModified(models.ChangeEvent{Project: req.Project, Tag: req.Tag})
+
 	utils.SuccessResponse(c, nil)
 }
 
@@ -112,6 +159,10 @@ func (h *Handlers) DeleteDownloadSource(c *gin.Context) {
 		return
 	}
 
+	if !h.requireScope(c, utils.ScopeDelete, req.Project) {
+		return
+	}
+
 	// 检查下载源是否存在
 	downloadSources, err := h.services.Build.GetDownloadSources(req.Project, req.Tag)
 	if err != nil {
@@ -144,9 +195,20 @@ func (h *Handlers) DeleteDownloadSource(c *gin.Context) {
 		return
 	}
 
+	h.markProjectModified(models.ChangeEvent{Project: req.Project, Tag: req.Tag})
+
 	utils.SuccessResponse(c, nil)
 }
 
+// markProjectModified 更新项目的修改时间，并清除本实例与其他实例中该项目的响应缓存
+// 失败时只记录日志，不影响已完成的写入
+func (h *Handlers) markProjectModified(event models.ChangeEvent) {
+	if err := h.services.Project.MarkModified(event.Project); err != nil {
+		logger.Errorf("Failed to mark project %s as modified: %v", event.Project, err)
+	}
+	h.services.Invalidation.Changed(event)
+}
+
 func (h *Handlers) triggerWebhook(projectID, version, tag string) {
 	if h.config.Webhook.CommitBuildURL == "" {
 		return
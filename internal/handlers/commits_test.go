@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"testing"
+	"webapi/internal/models"
+)
+
+func TestVersionLifecycleWarnings(t *testing.T) {
+	if warnings, err := versionLifecycleWarnings("1.21.4", models.VersionStatusSupported, "reject"); err != nil || len(warnings) != 0 {
+		t.Errorf("expected supported versions to accept builds silently, got %v %v", warnings, err)
+	}
+	if warnings, err := versionLifecycleWarnings("1.20.6", models.VersionStatusDeprecated, "reject"); err != nil || len(warnings) != 1 {
+		t.Errorf("expected a warning for deprecated versions, got %v %v", warnings, err)
+	}
+	if _, err := versionLifecycleWarnings("1.16.5", models.VersionStatusEOL, "reject"); err == nil {
+		t.Error("expected end-of-life versions to be rejected")
+	}
+	if warnings, err := versionLifecycleWarnings("1.16.5", models.VersionStatusEOL, "warn"); err != nil || len(warnings) != 1 {
+		t.Errorf("expected a warning for end-of-life versions under the warn policy, got %v %v", warnings, err)
+	}
+}
@@ -1,7 +1,14 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"webapi/internal/logger"
+	"webapi/internal/models"
 	"webapi/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -13,7 +20,17 @@ func (h *Handlers) DownloadBuild(c *gin.Context) {
 	buildIDStr := c.Param("build")
 	downloadSource := c.Param("download")
 
-	versionID, err := h.services.Version.GetVersionID(projectID, versionName)
+	// <download>.sha256 / <download>.sha512 为校验和附属文件
+	sidecar := ""
+	for _, algorithm := range []string{"sha256", "sha512"} {
+		if strings.HasSuffix(downloadSource, "."+algorithm) {
+			sidecar = algorithm
+			downloadSource = strings.TrimSuffix(downloadSource, "."+algorithm)
+			break
+		}
+	}
+
+	versionID, versionName, err := h.resolveVersion(projectID, versionName)
 	if err != nil {
 		utils.NotFoundResponse(c)
 		return
@@ -31,12 +48,197 @@ func (h *Handlers) DownloadBuild(c *gin.Context) {
 		return
 	}
 
+	// 签名链接可以绕过构建的可见性限制
+	// 签名针对具体的版本名与构建号，路径中使用 latest 等别名时不接受签名
+	signed := false
+	if signature := c.Query("signature"); signature != "" {
+		expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusForbidden, "Invalid signature")
+			return
+		}
+		if c.Param("version") != versionName || buildIDStr != strconv.Itoa(build.BuildID) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Signed links must reference a concrete version and build")
+			return
+		}
+		if err := utils.VerifyDownloadSignature(h.config.Download.SigningSecret, projectID, versionName, build.BuildID, downloadSource, expires, signature); err != nil {
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+		signed = true
+	}
+
+	if build.Private && !signed {
+		utils.NotFoundResponse(c)
+		return
+	}
+
 	downloadURL, err := h.services.Download.GetDownloadURL(downloadSource, projectID, build.Tag)
 	if err != nil {
 		utils.NotFoundResponse(c)
 		return
 	}
 
-	c.Header("Content-Type", "application/java-archive")
+	if sidecar != "" {
+		h.serveChecksumSidecar(c, build, sidecar)
+		return
+	}
+
+	h.deliverDownload(c, build, versionName, downloadSource, downloadURL)
+}
+
+// deliverDownload 根据请求方法和代理配置返回元数据、传输文件或重定向到下载源
+func (h *Handlers) deliverDownload(c *gin.Context, build *models.Build, versionName, downloadSource, downloadURL string) {
+	// HEAD 只返回元数据，不做重定向
+	if c.Request.Method == http.MethodHead {
+		setIntegrityHeaders(c, build)
+		c.Status(http.StatusOK)
+		return
+	}
+
+	h.services.Stats.Record(models.DownloadRecord{
+		Project:        build.Project,
+		Version:        versionName,
+		BuildID:        build.BuildID,
+		DownloadSource: downloadSource,
+		UserAgent:      utils.UserAgentFamily(c.Request.UserAgent()),
+	})
+
+	if h.shouldProxyDownload(build.Project, downloadSource) {
+		setIntegrityHeaders(c, build)
+		h.serveProxiedDownload(c, build, downloadURL)
+		return
+	}
+
 	c.Redirect(http.StatusFound, downloadURL)
+}
+
+// setIntegrityHeaders 写入记录的校验和，供下载工具自动校验
+func setIntegrityHeaders(c *gin.Context, build *models.Build) {
+	digest, reprDigest := utils.DigestHeaders(build.SHA256, build.SHA512)
+	if digest != "" {
+		c.Header("Digest", digest)
+		c.Header("Repr-Digest", reprDigest)
+	}
+	c.Header("ETag", `"`+strings.ToLower(build.SHA256)+`"`)
+	c.Header("Last-Modified", build.Time.UTC().Format(http.TimeFormat))
+	c.Header("Content-Type", "application/java-archive")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", build.JarName))
+}
+
+// serveChecksumSidecar 以 sha256sum / sha512sum 兼容的格式返回校验和
+func (h *Handlers) serveChecksumSidecar(c *gin.Context, build *models.Build, algorithm string) {
+	checksum := build.SHA256
+	if algorithm == "sha512" {
+		checksum = build.SHA512
+	}
+	if checksum == "" {
+		utils.NotFoundResponse(c, fmt.Sprintf("No %s checksum recorded for this build", algorithm))
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.String(http.StatusOK, "%s  %s\n", strings.ToLower(checksum), build.JarName)
+}
+
+// shouldProxyDownload 判断该项目或下载源是否配置为由 API 直接传输文件
+func (h *Handlers) shouldProxyDownload(projectID, downloadSource string) bool {
+	for _, project := range h.config.Download.ProxyProjects {
+		if project == projectID {
+			return true
+		}
+	}
+	for _, source := range h.config.Download.ProxySources {
+		if source == downloadSource {
+			return true
+		}
+	}
+	return false
+}
+
+// serveProxiedDownload 通过本地缓存传输制品，Range 与条件请求由 http.ServeContent 处理
+func (h *Handlers) serveProxiedDownload(c *gin.Context, build *models.Build, downloadURL string) {
+	file, err := h.services.Artifacts.Open(build.SHA256, downloadURL)
+	if err != nil {
+		logger.Errorf("Failed to proxy download %s-%s: %v", build.Project, build.Tag, err)
+		utils.ErrorResponse(c, http.StatusBadGateway, "Failed to fetch artifact")
+		return
+	}
+	defer file.Close()
+
+	http.ServeContent(c.Writer, c.Request, build.JarName, build.Time, file)
+}
+
+func (h *Handlers) SignDownload(c *gin.Context) {
+	var req models.SignDownloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !h.requireScope(c, utils.ScopeAdmin, req.Project) {
+		return
+	}
+
+	if h.config.Download.SigningSecret == "" {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Signed downloads are not configured")
+		return
+	}
+
+	ttl := h.config.Download.SignedURLTTL
+	if req.ExpiresIn > 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if ttl > h.config.Download.SignedURLMax {
+		utils.BadRequestResponse(c, fmt.Sprintf("expires_in must not exceed %d seconds", int64(h.config.Download.SignedURLMax.Seconds())))
+		return
+	}
+
+	// 签名前将 latest 等别名解析为具体的版本名与构建号，链接不随别名变化
+	versionID, versionName, err := h.resolveVersion(req.Project, req.Version)
+	if err != nil {
+		utils.NotFoundResponse(c, "Version not found")
+		return
+	}
+
+	buildID, err := h.services.Build.ParseBuildID(req.Project, versionID, req.Build)
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	build, err := h.services.Build.GetBuild(req.Project, versionID, buildID)
+	if err != nil {
+		utils.NotFoundResponse(c, "Build not found")
+		return
+	}
+
+	found := false
+	for _, source := range build.DownloadSources {
+		if source == req.Download {
+			found = true
+			break
+		}
+	}
+	if !found {
+		utils.NotFoundResponse(c, fmt.Sprintf("Download source %s not found for build %d", req.Download, build.BuildID))
+		return
+	}
+
+	expires := time.Now().Add(ttl).Unix()
+	signature := utils.SignDownload(h.config.Download.SigningSecret, req.Project, versionName, build.BuildID, req.Download, expires)
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signature)
+
+	downloadPath := fmt.Sprintf("/v2/projects/%s/versions/%s/builds/%d/downloads/%s?%s",
+		url.PathEscape(req.Project), url.PathEscape(versionName), build.BuildID, url.PathEscape(req.Download), query.Encode())
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"url":     downloadPath,
+		"version": versionName,
+		"build":   build.BuildID,
+		"expires": time.Unix(expires, 0).UTC().Format(time.RFC3339),
+	})
 }
\ No newline at end of file
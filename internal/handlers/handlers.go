@@ -2,20 +2,65 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
+	"net/http"
 	"webapi/internal/config"
+	"webapi/internal/logger"
 	"webapi/internal/services"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type Handlers struct {
 	config   *config.Config
 	db       *sql.DB
 	services *services.Services
+	keys     *utils.KeySet
 }
 
-func New(cfg *config.Config, database *sql.DB) *Handlers {
+func New(cfg *config.Config, database *sql.DB, svc *services.Services, keys *utils.KeySet) *Handlers {
 	return &Handlers{
 		config:   cfg,
 		db:       database,
-		services: services.New(database),
+		services: svc,
+		keys:     keys,
 	}
 }
+
+// requireScope 校验当前 token 是否允许对项目执行 action，不允许时写入 403 响应并返回 false
+func (h *Handlers) requireScope(c *gin.Context, action, projectID string) bool {
+	claims, _ := c.Get(utils.ClaimsContextKey)
+	mapClaims, _ := claims.(jwt.MapClaims)
+
+	var err error
+	if c.GetString(utils.AuthMethodContextKey) == utils.AuthMethodGitHubOIDC {
+		err = h.checkRepositoryScope(mapClaims, action, projectID)
+	} else {
+		err = utils.CheckScope(mapClaims, action, projectID, h.config.JWT.AllowUnscopedTokens)
+		if err == nil && h.config.JWT.AllowUnscopedTokens && utils.IsUnscopedToken(mapClaims) {
+			logger.Warnf("Accepted token without projects/scopes claims from %s; such tokens are deprecated and will be rejected by default in the next release", c.ClientIP())
+		}
+	}
+	if err != nil {
+		logger.Warnf("Rejected %s %s from %s: %v", c.Request.Method, c.Request.URL.Path, c.ClientIP(), err)
+		utils.ErrorResponse(c, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		return false
+	}
+
+	return true
+}
+
+// checkRepositoryScope 校验 GitHub Actions OIDC token 的 repository 是否为项目的仓库
+func (h *Handlers) checkRepositoryScope(claims jwt.MapClaims, action, projectID string) error {
+	project, err := h.services.Project.GetByID(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to load project %s: %w", projectID, err)
+	}
+	if project == nil || project.Repo == "" {
+		return fmt.Errorf("project %s has no repository configured", projectID)
+	}
+
+	return utils.CheckRepositoryScope(claims, action, project.Repo, h.config.JWT.GitHubOIDC.Scopes)
+}
\ No newline at end of file
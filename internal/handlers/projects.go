@@ -41,10 +41,17 @@ func (h *Handlers) GetProject(c *gin.Context) {
 		return
 	}
 
+	versionMetadata, err := h.services.Version.GetProjectMetadata(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
 	utils.SuccessResponse(c, map[string]interface{}{
-		"project_id":      project.ID,
-		"project_name":    project.Name,
-		"versions":        versions,
-		"version_groups":  versionGroups,
+		"project_id":       project.ID,
+		"project_name":     project.Name,
+		"versions":         versions,
+		"version_groups":   versionGroups,
+		"version_metadata": versionMetadata,
 	})
 }
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"path/filepath"
+	"time"
 	"webapi/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -49,6 +50,11 @@ func (h *Handlers) ServeAPISpec(c *gin.Context) {
 	c.Data(http.StatusOK, "application/json", data)
 }
 
+func (h *Handlers) ServeJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS(time.Now()))
+}
+
 func (h *Handlers) Handle404(c *gin.Context) {
 	utils.NotFoundResponse(c)
 }
\ No newline at end of file
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+	"webapi/internal/models"
+	"webapi/internal/services"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetDownloadStats(c *gin.Context) {
+	projectID := c.Param("project")
+
+	if !h.requireScope(c, utils.ScopeAdmin, projectID) {
+		return
+	}
+
+	project, err := h.services.Project.GetByID(projectID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+	if project == nil {
+		utils.NotFoundResponse(c)
+		return
+	}
+
+	filter := models.DownloadStatsFilter{
+		Project:        projectID,
+		Version:        c.Query("version"),
+		DownloadSource: c.Query("source"),
+	}
+
+	if buildStr := c.Query("build"); buildStr != "" {
+		buildID, err := strconv.Atoi(buildStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid build")
+			return
+		}
+		filter.BuildID = buildID
+	}
+
+	if filter.Since, err = parseTimeParam(c.Query("since")); err != nil {
+		utils.BadRequestResponse(c, "Invalid since")
+		return
+	}
+	if filter.Until, err = parseTimeParam(c.Query("until")); err != nil {
+		utils.BadRequestResponse(c, "Invalid until")
+		return
+	}
+
+	interval := c.DefaultQuery("interval", "day")
+	groupBy := c.DefaultQuery("group_by", "version")
+	if !services.IsStatsInterval(interval) {
+		utils.BadRequestResponse(c, "Invalid interval")
+		return
+	}
+	if !services.IsStatsGroupBy(groupBy) {
+		utils.BadRequestResponse(c, "Invalid group_by")
+		return
+	}
+
+	total, err := h.services.Stats.GetTotal(filter)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	series, err := h.services.Stats.GetSeries(filter, interval)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	breakdown, err := h.services.Stats.GetBreakdown(filter, groupBy)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"project_id":   project.ID,
+		"project_name": project.Name,
+		"total":        total,
+		"interval":     interval,
+		"series":       series,
+		"group_by":     groupBy,
+		"breakdown":    breakdown,
+	})
+}
+
+// parseTimeParam 解析时间查询参数，支持 RFC3339 与纯日期两种格式，空字符串返回零值
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"webapi/internal/logger"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// globalProject 用于校验不属于任何单个项目的管理操作，要求 token 的 projects 包含 "*"
+const globalProject = "*"
+
+func (h *Handlers) RevokeToken(c *gin.Context) {
+	var req models.RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !h.requireScope(c, utils.ScopeAdmin, globalProject) {
+		return
+	}
+
+	if err := h.services.Revocation.Revoke(req); err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	logger.Infof("Token %s revoked: %s", req.JTI, req.Reason)
+	utils.SuccessResponse(c, nil)
+}
+
+func (h *Handlers) GetRevokedTokens(c *gin.Context) {
+	if !h.requireScope(c, utils.ScopeAdmin, globalProject) {
+		return
+	}
+
+	tokens, err := h.services.Revocation.GetAll()
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"tokens": tokens,
+	})
+}
@@ -30,17 +30,24 @@ func (h *Handlers) GetVersionGroup(c *gin.Context) {
 		return
 	}
 
-	_, versionNames, err := h.services.VersionGroup.GetVersionsByGroupID(projectID, versionGroupID)
+	versionIDs, versionNames, err := h.services.VersionGroup.GetVersionsByGroupID(projectID, versionGroupID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	versionMetadata, err := h.services.Version.GetMetadataByIDs(versionIDs)
 	if err != nil {
 		utils.InternalServerErrorResponse(c)
 		return
 	}
 
 	utils.SuccessResponse(c, map[string]interface{}{
-		"project_id":     project.ID,
-		"project_name":   project.Name,
-		"version_group":  family,
-		"versions":       versionNames,
+		"project_id":       project.ID,
+		"project_name":     project.Name,
+		"version_group":    family,
+		"versions":         versionNames,
+		"version_metadata": versionMetadata,
 	})
 }
 
@@ -74,29 +81,41 @@ func (h *Handlers) GetVersionGroupBuilds(c *gin.Context) {
 		return
 	}
 
-	builds, err := h.services.Build.GetBuildsByVersions(projectID, versionIDs)
+	versionMetadata, err := h.services.Version.GetMetadataByIDs(versionIDs)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
+	filter, paginated, err := parseBuildListFilter(c)
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	builds, next, err := h.services.Build.ListBuilds(projectID, versionIDs, filter)
 	if err != nil {
 		utils.InternalServerErrorResponse(c)
 		return
 	}
 
-	var buildResponses []interface{}
-	for _, build := range builds {
-		changes, err := h.services.Change.GetChangesByIDs([]int64(build.Changes))
-		if err != nil {
-			utils.InternalServerErrorResponse(c)
-			return
-		}
+	buildResponses, err := h.buildResponses(builds)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
 
-		buildResponse := utils.BuildToBuildResponse(build, changes)
-		buildResponses = append(buildResponses, buildResponse)
+	response := map[string]interface{}{
+		"project_id":       project.ID,
+		"project_name":     project.Name,
+		"version_group":    family,
+		"versions":         versionNames,
+		"version_metadata": versionMetadata,
+		"builds":           buildResponses,
+	}
+	if paginated {
+		response["next"] = nextBuildCursor(next)
 	}
 
-	utils.SuccessResponse(c, map[string]interface{}{
-		"project_id":     project.ID,
-		"project_name":   project.Name,
-		"version_group":  family,
-		"versions":       versionNames,
-		"builds":         buildResponses,
-	})
+	utils.SuccessResponse(c, response)
 }
\ No newline at end of file
@@ -21,7 +21,7 @@ func (h *Handlers) GetVersion(c *gin.Context) {
 		return
 	}
 
-	versionID, err := h.services.Version.GetVersionID(projectID, versionName)
+	versionID, versionName, err := h.resolveVersion(projectID, versionName)
 	if err != nil {
 		utils.NotFoundResponse(c)
 		return
@@ -33,6 +33,12 @@ func (h *Handlers) GetVersion(c *gin.Context) {
 		return
 	}
 
+	metadata, err := h.services.Version.GetMetadata(versionID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
+
 	var buildIDs []int
 	for _, build := range builds {
 		buildIDs = append(buildIDs, build.BuildID)
@@ -42,6 +48,10 @@ func (h *Handlers) GetVersion(c *gin.Context) {
 		"project_id":   project.ID,
 		"project_name": project.Name,
 		"version":      versionName,
+		"release_date": metadata.ReleaseDate,
+		"status":       metadata.Status,
+		"java":         metadata.Java,
+		"notice":       metadata.Notice,
 		"builds":       buildIDs,
 	})
 }
@@ -60,36 +70,41 @@ func (h *Handlers) GetVersionBuilds(c *gin.Context) {
 		return
 	}
 
-	versionID, err := h.services.Version.GetVersionID(projectID, versionName)
+	versionID, versionName, err := h.resolveVersion(projectID, versionName)
 	if err != nil {
 		utils.NotFoundResponse(c)
 		return
 	}
 
-	builds, err := h.services.Build.GetBuildsByVersion(projectID, versionID)
+	filter, paginated, err := parseBuildListFilter(c)
 	if err != nil {
-		utils.InternalServerErrorResponse(c)
+		utils.BadRequestResponse(c, err.Error())
 		return
 	}
 
-	var buildResponses []interface{}
-	for _, build := range builds {
-		changes, err := h.services.Change.GetChangesByIDs([]int64(build.Changes))
-		if err != nil {
-			utils.InternalServerErrorResponse(c)
-			return
-		}
+	builds, next, err := h.services.Build.ListBuilds(projectID, []int{versionID}, filter)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
+	}
 
-		buildResponse := utils.BuildToBuildResponse(build, changes)
-		buildResponses = append(buildResponses, buildResponse)
+	buildResponses, err := h.buildResponses(builds)
+	if err != nil {
+		utils.InternalServerErrorResponse(c)
+		return
 	}
 
-	utils.SuccessResponse(c, map[string]interface{}{
+	response := map[string]interface{}{
 		"project_id":   project.ID,
 		"project_name": project.Name,
 		"version":      versionName,
 		"builds":       buildResponses,
-	})
+	}
+	if paginated {
+		response["next"] = nextBuildCursor(next)
+	}
+
+	utils.SuccessResponse(c, response)
 }
 
 func (h *Handlers) GetBuild(c *gin.Context) {
@@ -107,7 +122,7 @@ func (h *Handlers) GetBuild(c *gin.Context) {
 		return
 	}
 
-	versionID, err := h.services.Version.GetVersionID(projectID, versionName)
+	versionID, versionName, err := h.resolveVersion(projectID, versionName)
 	if err != nil {
 		utils.NotFoundResponse(c)
 		return
@@ -119,8 +134,9 @@ func (h *Handlers) GetBuild(c *gin.Context) {
 		return
 	}
 
+	// 私有构建只能通过签名下载链接访问
 	build, err := h.services.Build.GetBuild(projectID, versionID, buildID)
-	if err != nil {
+	if err != nil || build.Private {
 		utils.NotFoundResponse(c)
 		return
 	}
@@ -154,7 +170,7 @@ func (h *Handlers) GetLatestGroupBuildId(c *gin.Context) {
 	projectID := c.Param("project")
 	versionName := c.Param("version")
 
-	versionID, err := h.services.Version.GetVersionID(projectID, versionName)
+	versionID, versionName, err := h.resolveVersion(projectID, versionName)
 	if err != nil {
 		utils.NotFoundResponse(c)
 		return
@@ -187,7 +203,7 @@ func (h *Handlers) GetVersionDiffer(c *gin.Context) {
 	versionName := c.Param("version")
 	verRef := c.Param("verRef")
 
-	versionID, err := h.services.Version.GetVersionID(projectID, versionName)
+	versionID, versionName, err := h.resolveVersion(projectID, versionName)
 	if err != nil {
 		utils.NotFoundResponse(c)
 		return
@@ -215,4 +231,18 @@ func (h *Handlers) GetVersionDiffer(c *gin.Context) {
 
 	c.Header("Content-Type", "text/plain")
 	c.String(200, strconv.Itoa(diff))
-}
\ No newline at end of file
+}
+
+// resolveVersion 查找版本 ID，latest 解析为按项目版本号规则最新的版本
+func (h *Handlers) resolveVersion(projectID, versionName string) (int, string, error) {
+	if versionName == "latest" {
+		latest, err := h.services.Project.GetLatestVersion(projectID)
+		if err != nil {
+			return 0, "", err
+		}
+		versionName = latest
+	}
+
+	versionID, err := h.services.Version.GetVersionID(projectID, versionName)
+	return versionID, versionName, err
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"webapi/internal/logger"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuditRecorder 持久化审计记录
+type AuditRecorder interface {
+	Record(entry models.AuditEntry) error
+}
+
+// MaxAuditedBodySize 认证路由接受的最大请求体，足以容纳带完整变更日志的构建提交
+const MaxAuditedBodySize = 1 << 20
+
+// Audit 记录经过认证路由的每一次调用，需放在 Authentication 之前以便同时记录被拒绝的请求
+// 请求体在认证前读取，超过 MaxAuditedBodySize 时返回 413 并记录为 error
+func Audit(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxAuditedBodySize))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+						"code": 413,
+						"msg":  fmt.Sprintf("Request body must not exceed %d bytes", MaxAuditedBodySize),
+					})
+				} else {
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+						"code": 400,
+						"msg":  "Failed to read request body",
+					})
+				}
+				recordAudit(c, recorder, nil)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		c.Next()
+
+		recordAudit(c, recorder, body)
+	}
+}
+
+// recordAudit 写入一条审计记录，读取失败的请求体按空内容计算哈希
+func recordAudit(c *gin.Context, recorder AuditRecorder, body []byte) {
+	digest := sha256.Sum256(body)
+	entry := models.AuditEntry{
+		Time:       time.Now(),
+		IP:         c.ClientIP(),
+		Method:     c.Request.Method,
+		Endpoint:   c.Request.URL.Path,
+		BodySHA256: hex.EncodeToString(digest[:]),
+		Status:     c.Writer.Status(),
+		Result:     auditResult(c.Writer.Status()),
+	}
+	if claims, ok := c.Get(utils.ClaimsContextKey); ok {
+		if mapClaims, ok := claims.(jwt.MapClaims); ok {
+			entry.Subject, _ = mapClaims["sub"].(string)
+			entry.JTI, _ = mapClaims["jti"].(string)
+		}
+	}
+
+	if err := recorder.Record(entry); err != nil {
+		logger.Errorf("Failed to write audit log for %s %s: %v", entry.Method, entry.Endpoint, err)
+	}
+}
+
+func auditResult(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "denied"
+	case status >= 400:
+		return "error"
+	}
+	return "success"
+}
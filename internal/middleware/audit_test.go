@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type memoryAuditRecorder struct {
+	entries []models.AuditEntry
+}
+
+func (r *memoryAuditRecorder) Record(entry models.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestAuditRecordsAuthenticatedCall(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &memoryAuditRecorder{}
+	body := `{"project_id":"mint"}`
+
+	router := gin.New()
+	router.POST("/v2/commit/build",
+		Audit(recorder),
+		func(c *gin.Context) {
+			c.Set(utils.ClaimsContextKey, jwt.MapClaims{"sub": "mentha-ci", "jti": "token-1"})
+		},
+		func(c *gin.Context) {
+			data, _ := io.ReadAll(c.Request.Body)
+			if string(data) != body {
+				t.Errorf("handler got body %q, want %q", data, body)
+			}
+			c.Status(http.StatusForbidden)
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/commit/build", strings.NewReader(body))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(recorder.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	digest := sha256.Sum256([]byte(body))
+
+	if entry.Subject != "mentha-ci" || entry.JTI != "token-1" {
+		t.Errorf("unexpected principal %q / %q", entry.Subject, entry.JTI)
+	}
+	if entry.BodySHA256 != hex.EncodeToString(digest[:]) {
+		t.Errorf("unexpected body digest %s", entry.BodySHA256)
+	}
+	if entry.Endpoint != "/v2/commit/build" || entry.Status != http.StatusForbidden || entry.Result != "denied" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
+
+func TestAuditRejectsOversizedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &memoryAuditRecorder{}
+
+	reached := false
+	router := gin.New()
+	router.POST("/v2/commit/build", Audit(recorder), func(c *gin.Context) {
+		reached = true
+	})
+
+	body := strings.Repeat("x", MaxAuditedBodySize+1)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v2/commit/build", strings.NewReader(body)))
+
+	if w.Code != http.StatusRequestEntityTooLarge || reached {
+		t.Fatalf("expected 413 before reaching the handler, got %d (handler reached: %v)", w.Code, reached)
+	}
+	if len(recorder.entries) != 1 || recorder.entries[0].Result != "error" || recorder.entries[0].Status != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected the oversized request to be audited as error, got %+v", recorder.entries)
+	}
+}
@@ -1,19 +1,64 @@
 package middleware
 
 import (
+	"crypto/x509"
 	"net/http"
 	"strings"
 	"webapi/internal/config"
+	"webapi/internal/logger"
+	"webapi/internal/models"
 	"webapi/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-func Authentication(jwtConfig config.JWTConfig) gin.HandlerFunc {
+// RevocationChecker 判断 token 的 jti 是否已被吊销
+type RevocationChecker interface {
+	IsRevoked(jti string) bool
+}
+
+// ServiceAccountAuthenticator 按 API key 查找服务账号，找不到或已过期时返回 nil
+type ServiceAccountAuthenticator interface {
+	Authenticate(key string) (*models.ServiceAccount, error)
+}
+
+// Authenticator 认证中间件的依赖
+type Authenticator struct {
+	JWT  config.JWTConfig
+	Keys *utils.KeySet
+	// Revocations 为 nil 时不检查吊销列表
+	Revocations RevocationChecker
+	// GitHubOIDC 为 nil 时不接受 GitHub Actions OIDC token
+	GitHubOIDC *utils.OIDCVerifier
+	// ServiceAccounts 为 nil 时不接受 API key
+	ServiceAccounts ServiceAccountAuthenticator
+	// ClientCerts 为 nil 时不接受客户端证书
+	ClientCerts *utils.ClientCertMapper
+	// RequireClientCert 为 true 时只接受客户端证书
+	RequireClientCert bool
+}
+
+func Authentication(auth Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取 Authentication 头
-		authHeader := c.GetHeader("Authentication")
-		if authHeader == "" {
+		// 已通过 TLS 握手验证的客户端证书优先于请求头中的凭据
+		if auth.ClientCerts != nil && c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			authenticateClientCert(c, auth.ClientCerts, c.Request.TLS.VerifiedChains[0][0])
+			return
+		}
+		if auth.RequireClientCert {
+			logger.Warnf("Rejected %s %s from %s without client certificate", c.Request.Method, c.Request.URL.Path, c.ClientIP())
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": 401,
+				"msg":  "Client certificate required",
+			})
+			c.Abort()
+			return
+		}
+
+		// 获取凭据，兼容 Authentication 头与标准的 Authorization: Bearer
+		token := bearerCredential(c)
+		if token == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code": 401,
 				"msg":  "Unauthorized",
@@ -22,14 +67,28 @@ func Authentication(jwtConfig config.JWTConfig) gin.HandlerFunc {
 			return
 		}
 
-		// 移除 Bearer 前缀（如果存在）
-		token := authHeader
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			token = strings.TrimPrefix(authHeader, "Bearer ")
+		// 服务账号 API key 由固定前缀区分
+		if auth.ServiceAccounts != nil && utils.IsAPIKey(token) {
+			authenticateServiceAccount(c, auth.ServiceAccounts, token)
+			return
+		}
+
+		// GitHub Actions OIDC token 由 issuer 区分，项目权限在 handler 中按 repository claim 校验
+		method := utils.AuthMethodJWT
+		if auth.GitHubOIDC != nil && utils.TokenIssuer(token) == auth.GitHubOIDC.Issuer() {
+			method = utils.AuthMethodGitHubOIDC
 		}
 
 		// 验证 JWT
-		if err := utils.ValidateJWT(token, jwtConfig); err != nil {
+		var claims jwt.MapClaims
+		var err error
+		if method == utils.AuthMethodGitHubOIDC {
+			claims, err = auth.GitHubOIDC.Verify(token)
+		} else {
+			claims, err = utils.ValidateJWT(token, auth.Keys, auth.JWT)
+		}
+		if err != nil {
+			logger.Warnf("Rejected %s token from %s: %v", method, c.ClientIP(), err)
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code": 401,
 				"msg":  "Unauthorized",
@@ -38,6 +97,108 @@ func Authentication(jwtConfig config.JWTConfig) gin.HandlerFunc {
 			return
 		}
 
+		// 签发的 token 必须带 jti 才能吊销，GitHub Actions OIDC token 有效期很短，不做要求
+		jti, _ := claims["jti"].(string)
+		if method == utils.AuthMethodJWT && jti == "" {
+			if !auth.JWT.AllowTokensWithoutJTI {
+				logger.Warnf("Rejected token without jti from %s", c.ClientIP())
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code": 401,
+					"msg":  "Token has no jti and cannot be revoked. Please issue a new token",
+				})
+				c.Abort()
+				return
+			}
+			logger.Warnf("Accepted token without jti from %s; such tokens are deprecated and will be rejected by default in the next release", c.ClientIP())
+		}
+
+		// 拒绝已吊销的 token
+		if jti != "" && auth.Revocations != nil && auth.Revocations.IsRevoked(jti) {
+			logger.Warnf("Rejected revoked token %s from %s", jti, c.ClientIP())
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": 401,
+				"msg":  "Token has been revoked",
+			})
+			c.Abort()
+			return
+		}
+
+		// 验证 aud 是否允许访问当前接口，OIDC token 的 aud 已在验证时与配置比对
+		audiences, err := claims.GetAudience()
+		if method == utils.AuthMethodJWT && (err != nil || !utils.AudienceMatches(audiences, c.Request.URL.Path)) {
+			logger.Warnf("JWT audience %v does not match %s %s from %s", []string(audiences), c.Request.Method, c.Request.URL.Path, c.ClientIP())
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": 403,
+				"msg":  "Token audience does not allow this endpoint",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(utils.ClaimsContextKey, claims)
+		c.Set(utils.AuthMethodContextKey, method)
 		c.Next()
 	}
+}
+
+// bearerCredential 读取 Authentication 头，未设置时读取 Authorization 头，并移除 Bearer 前缀
+func bearerCredential(c *gin.Context) string {
+	header := c.GetHeader("Authentication")
+	if header == "" {
+		header = c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			return ""
+		}
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+}
+
+// authenticateServiceAccount 校验 API key，并将服务账号的项目与权限转换为 claims 供 handler 校验
+func authenticateServiceAccount(c *gin.Context, accounts ServiceAccountAuthenticator, key string) {
+	account, err := accounts.Authenticate(key)
+	if err != nil {
+		logger.Errorf("Failed to authenticate API key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "Internal server error",
+		})
+		c.Abort()
+		return
+	}
+	if account == nil {
+		logger.Warnf("Rejected unknown or expired API key from %s", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code": 401,
+			"msg":  "Unauthorized",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Set(utils.ClaimsContextKey, jwt.MapClaims{
+		"sub":      "service-account:" + account.Name,
+		"projects": account.Projects,
+		"scopes":   account.Scopes,
+	})
+	c.Set(utils.AuthMethodContextKey, utils.AuthMethodAPIKey)
+	c.Next()
+}
+
+// authenticateClientCert 按配置的映射将客户端证书转换为 claims，未配置的证书返回 403
+func authenticateClientCert(c *gin.Context, mapper *utils.ClientCertMapper, cert *x509.Certificate) {
+	claims, ok := mapper.Claims(cert)
+	if !ok {
+		logger.Warnf("Rejected client certificate %s from %s: no matching rule", cert.Subject, c.ClientIP())
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": 403,
+			"msg":  "Client certificate is not allowed",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Set(utils.ClaimsContextKey, claims)
+	c.Set(utils.AuthMethodContextKey, utils.AuthMethodClientCert)
+	c.Next()
 }
\ No newline at end of file
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type memoryServiceAccounts map[string]*models.ServiceAccount
+
+func (m memoryServiceAccounts) Authenticate(key string) (*models.ServiceAccount, error) {
+	return m[key], nil
+}
+
+func TestAuthenticationAcceptsServiceAccountKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, _, _, err := utils.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accounts := memoryServiceAccounts{
+		key: {Name: "mint-ci", Projects: []string{"mint"}, Scopes: []string{utils.ScopeCommitBuild}},
+	}
+
+	router := gin.New()
+	router.POST("/v2/commit/build",
+		Authentication(Authenticator{ServiceAccounts: accounts}),
+		func(c *gin.Context) {
+			claims, _ := c.Get(utils.ClaimsContextKey)
+			if err := utils.CheckScope(claims.(jwt.MapClaims), utils.ScopeCommitBuild, "mint", false); err != nil {
+				t.Errorf("expected service account scopes to allow commit: %v", err)
+			}
+			if method := c.GetString(utils.AuthMethodContextKey); method != utils.AuthMethodAPIKey {
+				t.Errorf("expected auth method %s, got %s", utils.AuthMethodAPIKey, method)
+			}
+			c.Status(http.StatusOK)
+		},
+	)
+
+	for _, tc := range []struct {
+		header, value string
+		status        int
+	}{
+		{"Authorization", "Bearer " + key, http.StatusOK},
+		{"Authentication", key, http.StatusOK},
+		{"Authorization", "Bearer " + utils.APIKeyPrefix + "unknown", http.StatusUnauthorized},
+		{"Authorization", key, http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/v2/commit/build", nil)
+		req.Header.Set(tc.header, tc.value)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: %q: expected status %d, got %d", tc.header, tc.value, tc.status, w.Code)
+		}
+	}
+}
+
+func TestAuthenticationRequiresClientCert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mapper, err := utils.ParseClientCertRules([]byte(`[{"subject": "runner-1", "projects": ["mint"], "scopes": ["commit_build"]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.POST("/v2/commit/build",
+		Authentication(Authenticator{ClientCerts: mapper, RequireClientCert: true}),
+		func(c *gin.Context) {
+			if method := c.GetString(utils.AuthMethodContextKey); method != utils.AuthMethodClientCert {
+				t.Errorf("expected auth method %s, got %s", utils.AuthMethodClientCert, method)
+			}
+			c.Status(http.StatusOK)
+		},
+	)
+
+	request := func(commonName string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v2/commit/build", nil)
+		req.Header.Set("Authentication", "Bearer some.jwt.token")
+		if commonName != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if status := request("runner-1"); status != http.StatusOK {
+		t.Errorf("expected mapped certificate to be accepted, got %d", status)
+	}
+	if status := request("runner-2"); status != http.StatusForbidden {
+		t.Errorf("expected unmapped certificate to be forbidden, got %d", status)
+	}
+	if status := request(""); status != http.StatusUnauthorized {
+		t.Errorf("expected request without certificate to be rejected, got %d", status)
+	}
+}
+
+type memoryRevocations map[string]bool
+
+func (m memoryRevocations) IsRevoked(jti string) bool {
+	return m[jti]
+}
+
+func TestAuthenticationRequiresRevocableJWT(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtConfig := config.JWTConfig{
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		Issuer:    "MenthaMC",
+		Subject:   "mentha-ci",
+		Algorithm: "ES256",
+	}
+	keys, err := utils.LoadKeySet(jwtConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(jti string) string {
+		claims := jwt.MapClaims{
+			"iss": "MenthaMC",
+			"sub": "mentha-ci",
+			"aud": "/v2/commit/build",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+		if jti != "" {
+			claims["jti"] = jti
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	legacyConfig := jwtConfig
+	legacyConfig.AllowTokensWithoutJTI = true
+
+	for _, tc := range []struct {
+		name   string
+		auth   Authenticator
+		token  string
+		status int
+	}{
+		{"no revocation store", Authenticator{JWT: jwtConfig, Keys: keys}, sign("token-1"), http.StatusOK},
+		{"not revoked", Authenticator{JWT: jwtConfig, Keys: keys, Revocations: memoryRevocations{}}, sign("token-1"), http.StatusOK},
+		{"revoked", Authenticator{JWT: jwtConfig, Keys: keys, Revocations: memoryRevocations{"token-1": true}}, sign("token-1"), http.StatusUnauthorized},
+		{"missing jti", Authenticator{JWT: jwtConfig, Keys: keys, Revocations: memoryRevocations{}}, sign(""), http.StatusUnauthorized},
+		{"missing jti allowed", Authenticator{JWT: legacyConfig, Keys: keys, Revocations: memoryRevocations{}}, sign(""), http.StatusOK},
+	} {
+		router := gin.New()
+		router.POST("/v2/commit/build", Authentication(tc.auth), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/v2/commit/build", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.status, w.Code)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+	"webapi/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ModificationLookup 返回项目数据的最后修改时间，projectID 为空表示所有项目，项目不存在时返回零值
+type ModificationLookup interface {
+	LastModified(projectID string) (time.Time, error)
+}
+
+// ConditionalGet 根据项目的最后修改时间生成强 ETag 与 Last-Modified
+// 客户端的 If-None-Match / If-Modified-Since 命中时直接返回 304，不再查询数据
+func ConditionalGet(lookup ModificationLookup, cacheControl string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		modified, err := lookup.LastModified(c.Param("project"))
+		if err != nil {
+			logger.Errorf("Failed to load last modification time for %s: %v", c.Request.URL.Path, err)
+			c.Next()
+			return
+		}
+		if modified.IsZero() {
+			// 项目不存在，交给 handler 返回 404
+			c.Next()
+			return
+		}
+
+		etag := resourceETag(c.Request.URL.RequestURI(), modified)
+		lastModified := modified.UTC().Format(http.TimeFormat)
+
+		if notModified(c.Request, etag, modified) {
+			header := c.Writer.Header()
+			header.Set("ETag", etag)
+			header.Set("Last-Modified", lastModified)
+			header.Set("Cache-Control", cacheControl)
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+
+		// 只为成功的响应附加缓存头
+		c.Writer = &conditionalWriter{
+			ResponseWriter: c.Writer,
+			headers: map[string]string{
+				"ETag":          etag,
+				"Last-Modified": lastModified,
+				"Cache-Control": cacheControl,
+			},
+		}
+		c.Next()
+	}
+}
+
+// resourceETag 由请求地址与修改时间计算，同一数据的不同查询参数得到不同的 ETag
+func resourceETag(uri string, modified time.Time) string {
+	sum := sha256.Sum256([]byte(uri + "\n" + modified.UTC().Format(time.RFC3339Nano)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified 按 RFC 7232 判断条件请求，存在 If-None-Match 时忽略 If-Modified-Since
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		return err == nil && !modified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+type conditionalWriter struct {
+	gin.ResponseWriter
+	headers map[string]string
+}
+
+func (w *conditionalWriter) WriteHeader(code int) {
+	if code == http.StatusOK {
+		for key, value := range w.headers {
+			w.Header().Set(key, value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memoryModifications map[string]time.Time
+
+func (m memoryModifications) LastModified(projectID string) (time.Time, error) {
+	return m[projectID], nil
+}
+
+func TestConditionalGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	modifications := memoryModifications{"mint": time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	calls := 0
+
+	router := gin.New()
+	router.GET("/v2/projects/:project", ConditionalGet(modifications, "public, max-age=30"), func(c *gin.Context) {
+		calls++
+		if c.Param("project") != "mint" {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 200})
+	})
+
+	request := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := request("/v2/projects/mint", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Cache-Control") != "public, max-age=30" {
+		t.Fatalf("unexpected first response %d %v", first.Code, first.Header())
+	}
+	if first.Header().Get("Last-Modified") != "Sat, 01 Jun 2024 12:00:00 GMT" {
+		t.Errorf("unexpected Last-Modified %q", first.Header().Get("Last-Modified"))
+	}
+
+	if w := request("/v2/projects/mint", map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified || calls != 1 {
+		t.Errorf("expected 304 without calling the handler, got %d after %d calls", w.Code, calls)
+	}
+	if w := request("/v2/projects/mint", map[string]string{"If-Modified-Since": "Sat, 01 Jun 2024 12:00:00 GMT"}); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for If-Modified-Since, got %d", w.Code)
+	}
+
+	modifications["mint"] = modifications["mint"].Add(time.Minute)
+	if w := request("/v2/projects/mint", map[string]string{"If-None-Match": etag}); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("expected a fresh response with a new ETag after modification, got %d", w.Code)
+	}
+
+	if w := request("/v2/projects/unknown", nil); w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" {
+		t.Errorf("expected 404 without ETag, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"webapi/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseStore 进程内的查询响应缓存
+type ResponseStore interface {
+	Enabled() bool
+	Get(project, key string) (models.CachedResponse, uint64, bool)
+	Set(project, key string, generation uint64, response models.CachedResponse)
+}
+
+// CacheResponses 命中时直接返回缓存的响应，未命中时缓存 handler 的 200 响应
+// 需放在 ConditionalGet 之后，使缓存的响应同样带有 ETag
+func CacheResponses(store ResponseStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !store.Enabled() {
+			c.Next()
+			return
+		}
+
+		project := c.Param("project")
+		key := c.Request.URL.RequestURI()
+
+		cached, generation, ok := store.Get(project, key)
+		if ok {
+			c.Header("X-Cache", "HIT")
+			c.Data(http.StatusOK, cached.ContentType, cached.Body)
+			c.Abort()
+			return
+		}
+
+		c.Header("X-Cache", "MISS")
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() == http.StatusOK {
+			store.Set(project, key, generation, models.CachedResponse{
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        recorder.body.Bytes(),
+			})
+		}
+	}
+}
+
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
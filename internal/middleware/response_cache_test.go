@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webapi/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memoryResponseStore map[string]models.CachedResponse
+
+func (m memoryResponseStore) Enabled() bool { return true }
+
+func (m memoryResponseStore) Get(project, key string) (models.CachedResponse, uint64, bool) {
+	response, ok := m[key]
+	return response, 0, ok
+}
+
+func (m memoryResponseStore) Set(project, key string, generation uint64, response models.CachedResponse) {
+	m[key] = response
+}
+
+func TestCacheResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memoryResponseStore{}
+	calls := 0
+
+	router := gin.New()
+	router.GET("/v2/projects/:project", CacheResponses(store), func(c *gin.Context) {
+		calls++
+		if c.Param("project") != "mint" {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 200, "project_id": "mint"})
+	})
+
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	miss := request("/v2/projects/mint")
+	hit := request("/v2/projects/mint")
+	if calls != 1 || hit.Header().Get("X-Cache") != "HIT" || hit.Body.String() != miss.Body.String() {
+		t.Errorf("expected the second request to be served from cache, calls=%d body=%q", calls, hit.Body.String())
+	}
+	if hit.Header().Get("Content-Type") != miss.Header().Get("Content-Type") {
+		t.Errorf("expected cached Content-Type %q, got %q", miss.Header().Get("Content-Type"), hit.Header().Get("Content-Type"))
+	}
+
+	request("/v2/projects/unknown")
+	request("/v2/projects/unknown")
+	if calls != 3 {
+		t.Errorf("expected error responses not to be cached, calls=%d", calls)
+	}
+}
@@ -5,10 +5,25 @@ import (
 	"github.com/lib/pq"
 )
 
+// Project 的版本号规则与自动创建设置只通过管理接口返回，见 AdminProject
 type Project struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	Repo string `json:"repo"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Repo          string `json:"repo"`
+	VersionScheme string `json:"-"`
+	// AutoProvision 为 true 时，提交构建遇到不存在的版本会按 VersionGroupRule 自动创建版本与版本组
+	AutoProvision    bool   `json:"-"`
+	VersionGroupRule string `json:"-"`
+}
+
+// AdminProject 管理接口返回的项目，包含全部设置
+type AdminProject struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Repo             string `json:"repo"`
+	VersionScheme    string `json:"version_scheme"`
+	AutoProvision    bool   `json:"auto_provision"`
+	VersionGroupRule string `json:"version_group_rule"`
 }
 
 type Version struct {
@@ -16,6 +31,28 @@ type Version struct {
 	Name         string `json:"name"`
 	Project      string `json:"project"`
 	VersionGroup int    `json:"version_group"`
+	VersionMetadata
+}
+
+// 版本的支持状态
+const (
+	VersionStatusSupported  = "supported"
+	VersionStatusDeprecated = "deprecated"
+	VersionStatusEOL        = "eol"
+)
+
+// VersionMetadata 版本的发布日期、支持状态与运行要求
+type VersionMetadata struct {
+	ReleaseDate *string     `json:"release_date"`
+	Status      string      `json:"status"`
+	Java        VersionJava `json:"java"`
+	Notice      string      `json:"notice"`
+}
+
+// VersionJava 版本要求的 Java 主版本号，未设置时为 null
+type VersionJava struct {
+	Minimum     *int `json:"minimum"`
+	Recommended *int `json:"recommended"`
 }
 
 type VersionGroup struct {
@@ -32,10 +69,12 @@ type Build struct {
 	Experimental    bool          `json:"experimental"`
 	JarName         string        `json:"jar_name"`
 	SHA256          string        `json:"sha256"`
+	SHA512          string        `json:"sha512"`
 	Version         int           `json:"version"`
 	Tag             string        `json:"tag"`
 	Changes         pq.Int64Array `json:"changes"`
 	DownloadSources pq.StringArray `json:"download_sources"`
+	Private         bool           `json:"private"`
 }
 
 type BuildResponse struct {
@@ -61,6 +100,14 @@ type ChangeResponse struct {
 	Message string `json:"message"`
 }
 
+// ChangelogBuild 变更日志中的一个构建及其首次出现的变更
+type ChangelogBuild struct {
+	Build   int              `json:"build"`
+	Version string           `json:"version"`
+	Time    string           `json:"time"`
+	Changes []ChangeResponse `json:"changes"`
+}
+
 type Download struct {
 	ID             int    `json:"id"`
 	Project        string `json:"project"`
@@ -72,6 +119,7 @@ type Download struct {
 type DownloadInfo struct {
 	Name   string `json:"name"`
 	SHA256 string `json:"sha256"`
+	SHA512 string `json:"sha512,omitempty"`
 }
 
 type CommitBuildRequest struct {
@@ -81,7 +129,9 @@ type CommitBuildRequest struct {
 	Changes   string `json:"changes" binding:"required"`
 	JarName   string `json:"jar_name" binding:"required"`
 	SHA256    string `json:"sha256" binding:"required"`
+	SHA512    string `json:"sha512"`
 	Tag       string `json:"tag" binding:"required"`
+	Private   bool   `json:"private"`
 }
 
 type CommitDownloadSourceRequest struct {
@@ -95,4 +145,180 @@ type DeleteDownloadSourceRequest struct {
 	DownloadSource string `json:"download_source" binding:"required"`
 	Project        string `json:"project" binding:"required"`
 	Tag            string `json:"tag" binding:"required"`
-}
\ No newline at end of file
+}
+
+type DownloadRecord struct {
+	Project        string    `json:"project"`
+	Version        string    `json:"version"`
+	BuildID        int       `json:"build"`
+	DownloadSource string    `json:"download_source"`
+	Bucket         time.Time `json:"bucket"`
+	UserAgent      string    `json:"user_agent"`
+}
+
+type DownloadStatsFilter struct {
+	Project        string
+	Version        string
+	BuildID        int
+	DownloadSource string
+	Since          time.Time
+	Until          time.Time
+}
+
+type DownloadStatsPoint struct {
+	Time  string `json:"time"`
+	Count int64  `json:"count"`
+}
+
+type DownloadStatsGroup struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+type SignDownloadRequest struct {
+	Project   string `json:"project" binding:"required"`
+	Version   string `json:"version" binding:"required"`
+	Build     string `json:"build" binding:"required"`
+	Download  string `json:"download" binding:"required"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+type CreateProjectRequest struct {
+	ID               string `json:"id" binding:"required"`
+	Name             string `json:"name" binding:"required"`
+	Repo             string `json:"repo"`
+	VersionScheme    string `json:"version_scheme"`
+	AutoProvision    bool   `json:"auto_provision"`
+	VersionGroupRule string `json:"version_group_rule"`
+}
+
+// UpdateProjectRequest 只修改请求中给出的字段
+type UpdateProjectRequest struct {
+	Name             *string `json:"name"`
+	Repo             *string `json:"repo"`
+	VersionScheme    *string `json:"version_scheme"`
+	AutoProvision    *bool   `json:"auto_provision"`
+	VersionGroupRule *string `json:"version_group_rule"`
+}
+
+type VersionGroupRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// VersionRequest 创建版本时 name 与 version_group 必填；修改时只修改请求中给出的字段
+// release_date 为空字符串、java_minimum/java_recommended 为 0 表示清除
+type VersionRequest struct {
+	Name            *string `json:"name"`
+	VersionGroup    *string `json:"version_group"`
+	ReleaseDate     *string `json:"release_date"`
+	Status          *string `json:"status"`
+	JavaMinimum     *int    `json:"java_minimum"`
+	JavaRecommended *int    `json:"java_recommended"`
+	Notice          *string `json:"notice"`
+}
+
+type RevokeTokenRequest struct {
+	JTI       string     `json:"jti" binding:"required"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RevokedToken struct {
+	JTI       string     `json:"jti"`
+	Reason    string     `json:"reason"`
+	RevokedAt time.Time  `json:"revoked_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ServiceAccount struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	KeyPrefix  string     `json:"key_prefix"`
+	Projects   []string   `json:"projects"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RotatedAt  *time.Time `json:"rotated_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	Subject    string    `json:"subject"`
+	JTI        string    `json:"jti"`
+	IP         string    `json:"ip"`
+	Method     string    `json:"method"`
+	Endpoint   string    `json:"endpoint"`
+	BodySHA256 string    `json:"body_sha256"`
+	Status     int       `json:"status"`
+	Result     string    `json:"result"`
+}
+
+type AuditFilter struct {
+	Subject  string
+	JTI      string
+	Endpoint string
+	Result   string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// BuildCursor 构建列表的分页位置，按 (build_id, id) 排序
+type BuildCursor struct {
+	BuildID int
+	ID      int
+}
+
+type BuildListFilter struct {
+	Since      time.Time
+	Until      time.Time
+	Channel    string
+	MinBuild   int
+	MaxBuild   int
+	Descending bool
+	After      *BuildCursor
+	// Limit 为 0 时不分页
+	Limit int
+}
+
+// CachedResponse 进程内缓存的查询接口响应
+type CachedResponse struct {
+	ContentType string
+	Body        []byte
+}
+
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Entries   int   `json:"entries"`
+	Size      int64 `json:"size"`
+	MaxSize   int64 `json:"max_size"`
+}
+
+// CommitResult 提交构建的结果
+type CommitResult struct {
+	Build int `json:"build"`
+	// Provisioned 本次提交自动创建了版本时不为 nil
+	Provisioned *ProvisionedVersion `json:"provisioned,omitempty"`
+}
+
+// ProvisionedVersion 提交构建时自动创建的版本
+type ProvisionedVersion struct {
+	Version      string `json:"version"`
+	VersionGroup string `json:"version_group"`
+	// CreatedVersionGroup 版本组是否也是本次创建的
+	CreatedVersionGroup bool `json:"created_version_group"`
+}
+
+// ChangeEvent 写操作影响的数据，通过 Postgres NOTIFY 广播给其他实例
+type ChangeEvent struct {
+	Project string `json:"project"`
+	Version string `json:"version,omitempty"`
+	Build   int    `json:"build,omitempty"`
+	Tag     string `json:"tag,omitempty"`
+	// Origin 发出事件的实例，用于忽略自己发出的通知
+	Origin string `json:"origin"`
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/logger"
+	"webapi/internal/utils"
+)
+
+// ArtifactCache 以 sha256 为键的本地磁盘 LRU 缓存，用于代理下载模式
+// 缓存目录在第一次代理下载时才创建，未启用代理模式时不访问磁盘
+type ArtifactCache struct {
+	dir     string
+	maxSize int64
+	client  *http.Client
+
+	loadOnce sync.Once
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List
+	size     int64
+	inflight map[string]*artifactFetch
+}
+
+type artifactEntry struct {
+	key  string
+	size int64
+}
+
+type artifactFetch struct {
+	done chan struct{}
+	err  error
+}
+
+func NewArtifactCache(cfg config.DownloadConfig) *ArtifactCache {
+	c := &ArtifactCache{
+		dir:      cfg.CacheDir,
+		maxSize:  cfg.CacheMaxSize,
+		client:   &http.Client{Timeout: 10 * time.Minute},
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		inflight: make(map[string]*artifactFetch),
+	}
+
+	return c
+}
+
+// load 从磁盘恢复缓存索引，按修改时间还原 LRU 顺序
+func (c *ArtifactCache) load() error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	type cachedFile struct {
+		key     string
+		size    int64
+		modTime time.Time
+	}
+
+	var cached []cachedFile
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		// 缓存文件名为小写的 sha256，其余为上次异常退出残留的临时文件
+		if !utils.IsSHA256Hex(file.Name()) || strings.ToLower(file.Name()) != file.Name() {
+			_ = os.Remove(filepath.Join(c.dir, file.Name()))
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		cached = append(cached, cachedFile{key: file.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+
+	sort.Slice(cached, func(i, j int) bool {
+		return cached[i].modTime.Before(cached[j].modTime)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, file := range cached {
+		c.entries[file.key] = c.lru.PushFront(&artifactEntry{key: file.key, size: file.size})
+		c.size += file.size
+	}
+	c.evictLocked()
+
+	return nil
+}
+
+// Open 返回缓存中的制品文件，未命中时从 sourceURL 下载并校验 sha256
+func (c *ArtifactCache) Open(sha256Hex, sourceURL string) (*os.File, error) {
+	key := strings.ToLower(sha256Hex)
+	if !utils.IsSHA256Hex(key) {
+		return nil, fmt.Errorf("invalid sha256 %q", sha256Hex)
+	}
+
+	c.loadOnce.Do(func() {
+		if err := c.load(); err != nil {
+			logger.Warnf("Failed to load artifact cache from %s: %v", c.dir, err)
+		}
+	})
+
+	for attempt := 0; attempt < 2; attempt++ {
+		c.mu.Lock()
+		if element, ok := c.entries[key]; ok {
+			c.lru.MoveToFront(element)
+			c.mu.Unlock()
+
+			file, err := os.Open(c.path(key))
+			if err == nil {
+				now := time.Now()
+				_ = os.Chtimes(c.path(key), now, now)
+				return file, nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+
+			// 文件被外部删除，移除索引后重新下载
+			c.mu.Lock()
+			c.removeLocked(key)
+			c.mu.Unlock()
+			continue
+		}
+
+		if fetch, ok := c.inflight[key]; ok {
+			c.mu.Unlock()
+			<-fetch.done
+			if fetch.err != nil {
+				return nil, fetch.err
+			}
+			continue
+		}
+
+		fetch := &artifactFetch{done: make(chan struct{})}
+		c.inflight[key] = fetch
+		c.mu.Unlock()
+
+		size, err := c.fetch(key, sourceURL)
+
+		c.mu.Lock()
+		delete(c.inflight, key)
+		if err == nil {
+			c.entries[key] = c.lru.PushFront(&artifactEntry{key: key, size: size})
+			c.size += size
+			c.evictLocked()
+		}
+		c.mu.Unlock()
+
+		fetch.err = err
+		close(fetch.done)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return os.Open(c.path(key))
+}
+
+func (c *ArtifactCache) fetch(key, sourceURL string) (int64, error) {
+	resp, err := c.client.Get(sourceURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch artifact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("upstream returned status %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to store artifact: %w", err)
+	}
+
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != key {
+		return 0, fmt.Errorf("sha256 mismatch: expected %s, got %s", key, actual)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// evictLocked 淘汰最久未使用的条目直到总大小不超过上限，至少保留最新的一个
+func (c *ArtifactCache) evictLocked() {
+	for c.size > c.maxSize && c.lru.Len() > 1 {
+		entry := c.lru.Back().Value.(*artifactEntry)
+		c.removeLocked(entry.key)
+		if err := os.Remove(c.path(entry.key)); err != nil && !os.IsNotExist(err) {
+			logger.Warnf("Failed to evict cached artifact %s: %v", entry.key, err)
+		}
+	}
+}
+
+func (c *ArtifactCache) removeLocked(key string) {
+	element, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.size -= element.Value.(*artifactEntry).size
+	c.lru.Remove(element)
+	delete(c.entries, key)
+}
+
+func (c *ArtifactCache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"webapi/internal/config"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestArtifactCacheFetchAndHit(t *testing.T) {
+	content := []byte("artifact content")
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	cache := NewArtifactCache(config.DownloadConfig{CacheDir: t.TempDir(), CacheMaxSize: 1 << 20})
+
+	for i := 0; i < 2; i++ {
+		file, err := cache.Open(sha256Hex(content), server.URL)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		file.Close()
+		if string(data) != string(content) {
+			t.Fatalf("unexpected content %q", data)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 upstream request, got %d", requests)
+	}
+}
+
+func TestArtifactCacheRejectsHashMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cache := NewArtifactCache(config.DownloadConfig{CacheDir: dir, CacheMaxSize: 1 << 20})
+
+	expected := sha256Hex([]byte("original"))
+	if _, err := cache.Open(expected, server.URL); err == nil {
+		t.Fatal("expected sha256 mismatch error")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, expected)); !os.IsNotExist(err) {
+		t.Error("mismatched artifact should not be cached")
+	}
+}
+
+func TestArtifactCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	artifacts := map[string][]byte{
+		"/a": []byte("aaaaaaaaaa"),
+		"/b": []byte("bbbbbbbbbb"),
+		"/c": []byte("cccccccccc"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(artifacts[r.URL.Path])
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cache := NewArtifactCache(config.DownloadConfig{CacheDir: dir, CacheMaxSize: 20})
+
+	open := func(path string) {
+		file, err := cache.Open(sha256Hex(artifacts[path]), server.URL+path)
+		if err != nil {
+			t.Fatalf("Open %s: %v", path, err)
+		}
+		file.Close()
+	}
+
+	open("/a")
+	open("/b")
+	open("/a")
+	open("/c")
+
+	if _, err := os.Stat(filepath.Join(dir, sha256Hex(artifacts["/b"]))); !os.IsNotExist(err) {
+		t.Error("expected least recently used artifact to be evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, sha256Hex(artifacts["/a"]))); err != nil {
+		t.Error("expected recently used artifact to stay cached")
+	}
+}
+
+func TestArtifactCacheCreatesDirectoryLazily(t *testing.T) {
+	content := []byte("artifact content")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "downloads")
+	cache := NewArtifactCache(config.DownloadConfig{CacheDir: dir, CacheMaxSize: 1 << 20})
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatal("expected the cache directory not to be created before the first proxied download")
+	}
+
+	file, err := cache.Open(sha256Hex(content), server.URL)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	file.Close()
+	if _, err := os.Stat(filepath.Join(dir, sha256Hex(content))); err != nil {
+		t.Errorf("expected the artifact to be cached: %v", err)
+	}
+}
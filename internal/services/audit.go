@@ -0,0 +1,95 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"webapi/internal/models"
+)
+
+type AuditService struct {
+	db *sql.DB
+}
+
+func NewAuditService(db *sql.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record 追加一条审计记录，audit_log 表只允许插入
+func (s *AuditService) Record(entry models.AuditEntry) error {
+	_, err := s.db.Exec(`
+		INSERT INTO audit_log (time, subject, jti, ip, method, endpoint, body_sha256, status, result)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, entry.Time, entry.Subject, entry.JTI, entry.IP, entry.Method, entry.Endpoint, entry.BodySHA256, entry.Status, entry.Result)
+
+	return err
+}
+
+// likeEscaper 转义 LIKE 模式中的通配符与转义字符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike 使 value 在 LIKE ... ESCAPE '\' 中按字面匹配
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+func (s *AuditService) Query(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Subject != "" {
+		add("subject = $%d", filter.Subject)
+	}
+	if filter.JTI != "" {
+		add("jti = $%d", filter.JTI)
+	}
+	if filter.Endpoint != "" {
+		add(`endpoint LIKE $%d ESCAPE '\'`, escapeLike(filter.Endpoint)+"%")
+	}
+	if filter.Result != "" {
+		add("result = $%d", filter.Result)
+	}
+	if !filter.Since.IsZero() {
+		add("time >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("time < $%d", filter.Until)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT id, time, subject, jti, ip, method, endpoint, body_sha256, status, result
+		FROM audit_log
+		%s
+		ORDER BY id DESC
+		LIMIT $%d
+	`, where, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		if err := rows.Scan(
+			&entry.ID, &entry.Time, &entry.Subject, &entry.JTI, &entry.IP,
+			&entry.Method, &entry.Endpoint, &entry.BodySHA256, &entry.Status, &entry.Result,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
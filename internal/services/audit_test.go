@@ -0,0 +1,17 @@
+package services
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	cases := map[string]string{
+		"/v2/projects": "/v2/projects",
+		"/v2/_":        `/v2/\_`,
+		"100%":         `100\%`,
+		`a\b`:          `a\\b`,
+	}
+	for value, expected := range cases {
+		if escaped := escapeLike(value); escaped != expected {
+			t.Errorf("escapeLike(%q) = %q, expected %q", value, escaped, expected)
+		}
+	}
+}
@@ -3,13 +3,25 @@ package services
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"webapi/internal/models"
 
 	"github.com/lib/pq"
 )
 
+// buildColumns 与 scanBuild 的扫描顺序保持一致
+const buildColumns = "id, project, build_id, time, experimental, jar_name, sha256, sha512, version, tag, changes, download_sources, private"
+
+// commitPrefixPattern 作为构建引用的提交哈希前缀至少 4 位
+var commitPrefixPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 type BuildService struct {
 	db *sql.DB
 }
@@ -18,11 +30,12 @@ func NewBuildService(db *sql.DB) *BuildService {
 	return &BuildService{db: db}
 }
 
+// GetBuildsByVersion 返回版本的公开构建
 func (s *BuildService) GetBuildsByVersion(projectID string, versionID int) ([]models.Build, error) {
 	rows, err := s.db.Query(`
-		SELECT id, project, build_id, time, experimental, jar_name, sha256, version, tag, changes, download_sources
+		SELECT `+buildColumns+`
 		FROM builds 
-		WHERE project = $1 AND version = $2
+		WHERE project = $1 AND version = $2 AND NOT private
 		ORDER BY build_id ASC
 	`, projectID, versionID)
 	if err != nil {
@@ -30,65 +43,97 @@ func (s *BuildService) GetBuildsByVersion(projectID string, versionID int) ([]mo
 	}
 	defer rows.Close()
 
-	var builds []models.Build
-	for rows.Next() {
-		var build models.Build
-		if err := rows.Scan(
-			&build.ID, &build.Project, &build.BuildID, &build.Time,
-			&build.Experimental, &build.JarName, &build.SHA256,
-			&build.Version, &build.Tag, &build.Changes, &build.DownloadSources,
-		); err != nil {
-			return nil, err
+	return scanBuilds(rows)
+}
+
+// ListBuilds 按过滤条件列出若干版本的公开构建，按 (build_id, id) 排序
+// 分页时多查询一条用于判断是否还有下一页，返回下一页的 cursor，没有更多数据时为 nil
+func (s *BuildService) ListBuilds(projectID string, versionIDs []int, filter models.BuildListFilter) ([]models.Build, *models.BuildCursor, error) {
+	if len(versionIDs) == 0 {
+		return []models.Build{}, nil, nil
+	}
+
+	conditions := []string{"project = $1", "version = ANY($2)", "NOT private"}
+	args := []interface{}{projectID, pq.Array(versionIDs)}
+
+	add := func(condition string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = len(args)
 		}
-		builds = append(builds, build)
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
 	}
 
-	return builds, nil
-}
+	if !filter.Since.IsZero() {
+		add("time >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("time < $%d", filter.Until)
+	}
+	if filter.Channel != "" {
+		add("experimental = $%d", filter.Channel == "experimental")
+	}
+	if filter.MinBuild > 0 {
+		add("build_id >= $%d", filter.MinBuild)
+	}
+	if filter.MaxBuild > 0 {
+		add("build_id <= $%d", filter.MaxBuild)
+	}
 
-func (s *BuildService) GetBuildsByVersions(projectID string, versionIDs []int) ([]models.Build, error) {
-	if len(versionIDs) == 0 {
-		return []models.Build{}, nil
+	order := "ASC"
+	if filter.Descending {
+		order = "DESC"
+	}
+	if filter.After != nil {
+		if filter.Descending {
+			add("(build_id, id) < ($%d, $%d)", filter.After.BuildID, filter.After.ID)
+		} else {
+			add("(build_id, id) > ($%d, $%d)", filter.After.BuildID, filter.After.ID)
+		}
 	}
 
-	rows, err := s.db.Query(`
-		SELECT id, project, build_id, time, experimental, jar_name, sha256, version, tag, changes, download_sources
-		FROM builds 
-		WHERE project = $1 AND version = ANY($2)
-		ORDER BY build_id ASC
-	`, projectID, pq.Array(versionIDs))
+	query := `
+		SELECT ` + buildColumns + `
+		FROM builds
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY build_id ` + order + `, id ` + order
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit+1)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var builds []models.Build
-	for rows.Next() {
-		var build models.Build
-		if err := rows.Scan(
-			&build.ID, &build.Project, &build.BuildID, &build.Time,
-			&build.Experimental, &build.JarName, &build.SHA256,
-			&build.Version, &build.Tag, &build.Changes, &build.DownloadSources,
-		); err != nil {
-			return nil, err
-		}
-		builds = append(builds, build)
+	builds, err := scanBuilds(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	if builds == nil {
+		builds = []models.Build{}
+	}
+
+	var next *models.BuildCursor
+	if filter.Limit > 0 && len(builds) > filter.Limit {
+		builds = builds[:filter.Limit]
+		last := builds[len(builds)-1]
+		next = &models.BuildCursor{BuildID: last.BuildID, ID: last.ID}
 	}
 
-	return builds, nil
+	return builds, next, nil
 }
 
+// GetBuild 返回指定构建，包括私有构建，由调用方决定是否可见
 func (s *BuildService) GetBuild(projectID string, versionID int, buildID int) (*models.Build, error) {
-	var build models.Build
-	err := s.db.QueryRow(`
-		SELECT id, project, build_id, time, experimental, jar_name, sha256, version, tag, changes, download_sources
+	build, err := scanBuild(s.db.QueryRow(`
+		SELECT `+buildColumns+`
 		FROM builds 
 		WHERE project = $1 AND version = $2 AND build_id = $3
-	`, projectID, versionID, buildID).Scan(
-		&build.ID, &build.Project, &build.BuildID, &build.Time,
-		&build.Experimental, &build.JarName, &build.SHA256,
-		&build.Version, &build.Tag, &build.Changes, &build.DownloadSources,
-	)
+	`, projectID, versionID, buildID))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -97,12 +142,32 @@ func (s *BuildService) GetBuild(projectID string, versionID int, buildID int) (*
 		return nil, err
 	}
 
-	return &build, nil
+	return build, nil
+}
+
+// GetBuildBySHA256 按制品哈希查找公开构建，存在重复提交时返回最新的一个
+func (s *BuildService) GetBuildBySHA256(projectID, sha256Hex string) (*models.Build, error) {
+	build, err := scanBuild(s.db.QueryRow(`
+		SELECT `+buildColumns+`
+		FROM builds
+		WHERE project = $1 AND lower(sha256) = lower($2) AND NOT private
+		ORDER BY time DESC
+		LIMIT 1
+	`, projectID, sha256Hex))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("build not found")
+		}
+		return nil, err
+	}
+
+	return build, nil
 }
 
 func (s *BuildService) ParseBuildID(projectID string, versionID int, buildIDStr string) (int, error) {
 	if buildIDStr == "latest" {
-		return s.getLatestBuildID(projectID, versionID)
+		return s.getLatestBuildID(projectID, []int{versionID})
 	}
 
 	buildID, err := strconv.Atoi(buildIDStr)
@@ -113,13 +178,14 @@ func (s *BuildService) ParseBuildID(projectID string, versionID int, buildIDStr
 	return buildID, nil
 }
 
-func (s *BuildService) getLatestBuildID(projectID string, versionID int) (int, error) {
+// getLatestBuildID 返回若干版本中最新的公开构建号
+func (s *BuildService) getLatestBuildID(projectID string, versionIDs []int) (int, error) {
 	var buildID int
 	err := s.db.QueryRow(`
 		SELECT COALESCE(MAX(build_id), 0) 
 		FROM builds 
-		WHERE project = $1 AND version = $2
-	`, projectID, versionID).Scan(&buildID)
+		WHERE project = $1 AND version = ANY($2) AND NOT private
+	`, projectID, pq.Array(versionIDs)).Scan(&buildID)
 	
 	if err != nil {
 		return 0, err
@@ -132,7 +198,107 @@ func (s *BuildService) getLatestBuildID(projectID string, versionID int) (int, e
 	return buildID, nil
 }
 
-func (s *BuildService) CreateBuild(req models.CommitBuildRequest, versionID int, buildID int, changes []int64) error {
+// Commit 在一个事务中插入变更并创建构建，构建号为版本组内最大构建号加一
+// versionGroup 不为空时，版本不存在则在该版本组下创建版本，版本组不存在时一并创建
+func (s *BuildService) Commit(req models.CommitBuildRequest, changes []models.ChangeResponse, versionGroup string) (*models.CommitResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &models.CommitResult{}
+
+	var versionID, versionGroupID int
+	err = tx.QueryRow(`
+		SELECT id, version_group FROM versions WHERE project = $1 AND name = $2
+	`, req.ProjectID, req.Version).Scan(&versionID, &versionGroupID)
+	if err == sql.ErrNoRows && versionGroup != "" {
+		versionID, versionGroupID, result.Provisioned, err = provisionVersion(tx, req.ProjectID, req.Version, versionGroup)
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("version not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 锁定版本组，避免并发提交得到相同的构建号
+	if _, err := tx.Exec("SELECT id FROM version_groups WHERE id = $1 FOR UPDATE", versionGroupID); err != nil {
+		return nil, err
+	}
+
+	var latestBuildID int
+	err = tx.QueryRow(`
+		SELECT COALESCE(MAX(b.build_id), 0)
+		FROM builds b
+		JOIN versions v ON v.id = b.version
+		WHERE b.project = $1 AND v.version_group = $2
+	`, req.ProjectID, versionGroupID).Scan(&latestBuildID)
+	if err != nil {
+		return nil, err
+	}
+	result.Build = latestBuildID + 1
+
+	changeIDs, err := insertChanges(tx, req.ProjectID, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertBuild(tx, req, versionID, result.Build, changeIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// provisionVersion 创建版本与所需的版本组
+// 并发请求已创建同名版本时沿用已存在的记录，此时返回的 provisioned 为 nil
+func provisionVersion(tx *sql.Tx, projectID, versionName, versionGroup string) (int, int, *models.ProvisionedVersion, error) {
+	provisioned := &models.ProvisionedVersion{Version: versionName, VersionGroup: versionGroup}
+
+	var versionGroupID int
+	err := tx.QueryRow(`
+		INSERT INTO version_groups (project, name) VALUES ($1, $2)
+		ON CONFLICT (project, name) DO NOTHING
+		RETURNING id
+	`, projectID, versionGroup).Scan(&versionGroupID)
+	switch {
+	case err == nil:
+		provisioned.CreatedVersionGroup = true
+	case err == sql.ErrNoRows:
+		err = tx.QueryRow(`
+			SELECT id FROM version_groups WHERE project = $1 AND name = $2
+		`, projectID, versionGroup).Scan(&versionGroupID)
+	}
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	var versionID int
+	err = tx.QueryRow(`
+		INSERT INTO versions (name, project, version_group) VALUES ($1, $2, $3)
+		ON CONFLICT (project, name) DO NOTHING
+		RETURNING id
+	`, versionName, projectID, versionGroupID).Scan(&versionID)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRow(`
+			SELECT id, version_group FROM versions WHERE project = $1 AND name = $2
+		`, projectID, versionName).Scan(&versionID, &versionGroupID)
+		provisioned = nil
+	}
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	return versionID, versionGroupID, provisioned, nil
+}
+
+func insertBuild(q queryer, req models.CommitBuildRequest, versionID int, buildID int, changes []int64) error {
 	experimental := req.Channel == "experimental"
 	tag := req.Tag
 	if len(req.Version) > 0 && len(tag) > len(req.Version)+1 {
@@ -141,10 +307,10 @@ func (s *BuildService) CreateBuild(req models.CommitBuildRequest, versionID int,
 		}
 	}
 
-	_, err := s.db.Exec(`
-		INSERT INTO builds (project, build_id, time, experimental, jar_name, sha256, version, tag, changes, download_sources)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`, req.ProjectID, buildID, time.Now(), experimental, req.JarName, req.SHA256, versionID, tag, pq.Array(changes), pq.Array([]string{"application"}))
+	_, err := q.Exec(`
+		INSERT INTO builds (project, build_id, time, experimental, jar_name, sha256, sha512, version, tag, changes, download_sources, private)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, req.ProjectID, buildID, time.Now(), experimental, req.JarName, req.SHA256, req.SHA512, versionID, tag, pq.Array(changes), pq.Array([]string{"application"}), req.Private)
 
 	return err
 }
@@ -184,4 +350,141 @@ func (s *BuildService) RemoveDownloadSource(projectID, tag, downloadSource strin
 	`, downloadSource, projectID, tag)
 
 	return err
-}
\ No newline at end of file
+}
+
+func scanBuild(row rowScanner) (*models.Build, error) {
+	var build models.Build
+	if err := row.Scan(
+		&build.ID, &build.Project, &build.BuildID, &build.Time,
+		&build.Experimental, &build.JarName, &build.SHA256, &build.SHA512,
+		&build.Version, &build.Tag, &build.Changes, &build.DownloadSources,
+		&build.Private,
+	); err != nil {
+		return nil, err
+	}
+	return &build, nil
+}
+
+func scanBuilds(rows *sql.Rows) ([]models.Build, error) {
+	var builds []models.Build
+	for rows.Next() {
+		build, err := scanBuild(rows)
+		if err != nil {
+			return nil, err
+		}
+		builds = append(builds, *build)
+	}
+
+	return builds, rows.Err()
+}
+
+// 构建引用的查找方式
+const (
+	buildRefLatest = iota
+	buildRefNumber
+	buildRefTag
+	buildRefCommit
+)
+
+// buildRefLookups 返回解析 ref 时依次尝试的查找方式
+// 纯数字的 ref 先作为构建号，没有该构建时再作为 tag 或提交哈希前缀
+func buildRefLookups(ref string) []int {
+	if ref == "latest" {
+		return []int{buildRefLatest}
+	}
+
+	var lookups []int
+	if number, err := strconv.Atoi(ref); err == nil && number > 0 {
+		lookups = append(lookups, buildRefNumber)
+	}
+	lookups = append(lookups, buildRefTag)
+	if commitPrefixPattern.MatchString(ref) {
+		lookups = append(lookups, buildRefCommit)
+	}
+	return lookups
+}
+
+// ResolveBuildRef 在若干版本的公开构建中解析构建引用，返回构建号
+// ref 可以是 latest、构建号、构建 tag（可带版本名前缀）或提交哈希前缀，提交对应其首次出现的构建
+func (s *BuildService) ResolveBuildRef(projectID string, versionIDs []int, ref string) (int, error) {
+	for _, lookup := range buildRefLookups(ref) {
+		var buildID int
+		var err error
+		switch lookup {
+		case buildRefLatest:
+			return s.getLatestBuildID(projectID, versionIDs)
+		case buildRefNumber:
+			number, _ := strconv.Atoi(ref)
+			err = s.db.QueryRow(`
+				SELECT build_id FROM builds
+				WHERE project = $1 AND version = ANY($2) AND build_id = $3 AND NOT private
+				LIMIT 1
+			`, projectID, pq.Array(versionIDs), number).Scan(&buildID)
+		case buildRefTag:
+			err = s.db.QueryRow(`
+				SELECT b.build_id
+				FROM builds b
+				JOIN versions v ON v.id = b.version
+				WHERE b.project = $1 AND b.version = ANY($2) AND NOT b.private
+				  AND (b.tag = $3 OR v.name || '-' || b.tag = $3)
+				ORDER BY b.build_id
+				LIMIT 1
+			`, projectID, pq.Array(versionIDs), ref).Scan(&buildID)
+		case buildRefCommit:
+			buildID, err = s.resolveCommitPrefix(projectID, versionIDs, ref)
+		}
+
+		if err == nil {
+			return buildID, nil
+		}
+		if err != sql.ErrNoRows {
+			return 0, err
+		}
+	}
+
+	return 0, fmt.Errorf("no build found for reference %s", ref)
+}
+
+// resolveCommitPrefix 返回首次包含该提交的公开构建，没有匹配的提交时返回 sql.ErrNoRows
+func (s *BuildService) resolveCommitPrefix(projectID string, versionIDs []int, prefix string) (int, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT c.commit
+		FROM builds b
+		JOIN changes c ON c.id = ANY(b.changes)
+		WHERE b.project = $1 AND b.version = ANY($2) AND NOT b.private AND c.commit LIKE $3
+		LIMIT 2
+	`, projectID, pq.Array(versionIDs), prefix+"%")
+	if err != nil {
+		return 0, err
+	}
+	var commits []string
+	for rows.Next() {
+		var commit string
+		if err := rows.Scan(&commit); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		commits = append(commits, commit)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	switch len(commits) {
+	case 0:
+		return 0, sql.ErrNoRows
+	case 1:
+	default:
+		return 0, fmt.Errorf("multiple commits found for reference prefix %s. Please specify a more precise reference", prefix)
+	}
+
+	var buildID int
+	err = s.db.QueryRow(`
+		SELECT MIN(b.build_id)
+		FROM builds b
+		JOIN changes c ON c.id = ANY(b.changes)
+		WHERE b.project = $1 AND b.version = ANY($2) AND NOT b.private AND c.commit = $3
+	`, projectID, pq.Array(versionIDs), commits[0]).Scan(&buildID)
+	return buildID, err
+}
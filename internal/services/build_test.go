@@ -0,0 +1,96 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"webapi/internal/models"
+)
+
+func TestBuildRefLookups(t *testing.T) {
+	cases := []struct {
+		ref      string
+		expected []int
+	}{
+		{"latest", []int{buildRefLatest}},
+		{"12", []int{buildRefNumber, buildRefTag}},
+		{"1234", []int{buildRefNumber, buildRefTag, buildRefCommit}},
+		{"20240101", []int{buildRefNumber, buildRefTag, buildRefCommit}},
+		{"0", []int{buildRefTag}},
+		{"-3", []int{buildRefTag}},
+		{"abcd", []int{buildRefTag, buildRefCommit}},
+		{"ABCDEF12", []int{buildRefTag, buildRefCommit}},
+		{"abc", []int{buildRefTag}},
+		{"1.21.4-12", []int{buildRefTag}},
+		{"rc1", []int{buildRefTag}},
+	}
+	for _, tc := range cases {
+		if lookups := buildRefLookups(tc.ref); !reflect.DeepEqual(lookups, tc.expected) {
+			t.Errorf("buildRefLookups(%q) = %v, expected %v", tc.ref, lookups, tc.expected)
+		}
+	}
+}
+
+// 以下测试需要 WEBAPI_TEST_DB_URL 指向已初始化的数据库
+
+func TestGetBuildBySHA256SkipsPrivateBuilds(t *testing.T) {
+	db := openTestDB(t, "WEBAPI_TEST_DB_URL")
+	project := newTestProject(t, db)
+
+	const hash = "3f786850e387550fdab836ed7e6dc881de23001b3f786850e387550fdab836ed"
+	at := time.Date(2024, 12, 3, 10, 0, 0, 0, time.UTC)
+	project.addBuild(t, db, "1.21.4", models.Build{BuildID: 1, SHA256: hash, Time: at})
+	// 之后以相同制品重新提交的私有构建不应遮蔽公开构建
+	project.addBuild(t, db, "1.21.4", models.Build{BuildID: 2, SHA256: strings.ToUpper(hash), Time: at.Add(time.Hour), Private: true})
+
+	build, err := NewBuildService(db).GetBuildBySHA256(project.ID, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if build.BuildID != 1 || build.Private {
+		t.Errorf("expected public build 1, got build %d (private %v)", build.BuildID, build.Private)
+	}
+}
+
+func TestResolveBuildRef(t *testing.T) {
+	db := openTestDB(t, "WEBAPI_TEST_DB_URL")
+	project := newTestProject(t, db)
+
+	project.addBuild(t, db, "1.21.3", models.Build{BuildID: 1, Tag: "t1"}, "aaaa1111")
+	project.addBuild(t, db, "1.21.3", models.Build{BuildID: 2, Tag: "rc1"}, "12345678")
+	project.addBuild(t, db, "1.21.4", models.Build{BuildID: 3, Tag: "t3", Private: true}, "dddd4444")
+	project.addBuild(t, db, "1.21.4", models.Build{BuildID: 4, Tag: "t4"}, "bbbb2222", "20240101ff")
+
+	group := []int{project.Versions["1.21.3"], project.Versions["1.21.4"]}
+	builds := NewBuildService(db)
+
+	for _, tc := range []struct {
+		ref      string
+		expected int
+	}{
+		{"latest", 4},
+		{"1", 1},
+		{"rc1", 2},
+		{"1.21.3-rc1", 2},
+		{"aaaa", 1},
+		// 纯数字的提交哈希前缀在没有同号构建时按提交解析
+		{"1234", 2},
+		{"20240101", 4},
+	} {
+		if buildID, err := builds.ResolveBuildRef(project.ID, group, tc.ref); err != nil || buildID != tc.expected {
+			t.Errorf("ResolveBuildRef(%q): expected %d, got %d %v", tc.ref, tc.expected, buildID, err)
+		}
+	}
+
+	// 私有构建的构建号、tag 与提交均不可引用
+	for _, ref := range []string{"3", "t3", "dddd", "nope", "99"} {
+		if buildID, err := builds.ResolveBuildRef(project.ID, group, ref); err == nil {
+			t.Errorf("ResolveBuildRef(%q): expected an error, got %d", ref, buildID)
+		}
+	}
+
+	if buildID, err := builds.ResolveBuildRef(project.ID, []int{project.Versions["1.21.3"]}, "latest"); err != nil || buildID != 2 {
+		t.Errorf("ResolveBuildRef(latest) in 1.21.3: expected 2, got %d %v", buildID, err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"webapi/internal/models"
+
+	"github.com/lib/pq"
 )
 
 type ChangeService struct {
@@ -53,12 +55,48 @@ func (s *ChangeService) GetChangesByIDs(changeIDs []int64) ([]models.ChangeRespo
 	return changes, nil
 }
 
+// GetChangesByBuilds 用一次查询加载多个构建的变更，返回以 builds.id 为键的变更列表
+// 每个构建的变更按 id 排序并去重，与 GetChangesByIDs 的结果一致
+func (s *ChangeService) GetChangesByBuilds(buildRowIDs []int) (map[int][]models.ChangeResponse, error) {
+	changes := make(map[int][]models.ChangeResponse)
+	if len(buildRowIDs) == 0 {
+		return changes, nil
+	}
+
+	rows, err := s.db.Query(`
+		SELECT b.id, c.commit, c.summary, c.message
+		FROM builds b
+		JOIN changes c ON c.id = ANY(b.changes)
+		WHERE b.id = ANY($1)
+		ORDER BY b.id, c.id
+	`, pq.Array(buildRowIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var buildRowID int
+		var change models.ChangeResponse
+		if err := rows.Scan(&buildRowID, &change.Commit, &change.Summary, &change.Message); err != nil {
+			return nil, err
+		}
+		changes[buildRowID] = append(changes[buildRowID], change)
+	}
+
+	return changes, rows.Err()
+}
+
 func (s *ChangeService) InsertChanges(projectID string, changesData []models.ChangeResponse) ([]int64, error) {
+	return insertChanges(s.db, projectID, changesData)
+}
+
+func insertChanges(q queryer, projectID string, changesData []models.ChangeResponse) ([]int64, error) {
 	var changeIDs []int64
 
 	for _, change := range changesData {
 		var changeID int64
-		err := s.db.QueryRow(`
+		err := q.QueryRow(`
 			INSERT INTO changes (project, commit, summary, message)
 			VALUES ($1, $2, $3, $4)
 			RETURNING id
@@ -103,11 +141,12 @@ func (s *ChangeService) GetChangeIDByCommitPrefix(projectID, commitPrefix string
 	return changeIDs[0], nil
 }
 
+// GetBuildIDByChange 返回包含该变更的公开构建号
 func (s *ChangeService) GetBuildIDByChange(versionID, changeID int) (int, error) {
 	var buildID int
 	err := s.db.QueryRow(`
 		SELECT build_id FROM builds 
-		WHERE version = $1 AND changes @> $2
+		WHERE version = $1 AND changes @> $2 AND NOT private
 	`, versionID, fmt.Sprintf("{%d}", changeID)).Scan(&buildID)
 
 	if err != nil {
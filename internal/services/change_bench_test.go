@@ -0,0 +1,65 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"webapi/internal/models"
+)
+
+// benchBuildCount 与一个较大的版本组规模相当
+const benchBuildCount = 500
+
+// openBenchDB 连接 WEBAPI_BENCH_DB_URL 指向的已初始化数据库，并写入一个临时项目的构建与变更
+// 未设置时跳过，例如：WEBAPI_BENCH_DB_URL=postgres://... go test -bench Changes ./internal/services
+func openBenchDB(b *testing.B) (*sql.DB, []models.Build) {
+	b.Helper()
+
+	db := openTestDB(b, "WEBAPI_BENCH_DB_URL")
+	project := newTestProject(b, db)
+
+	for i := 1; i <= benchBuildCount; i++ {
+		project.addBuild(b, db, "1.21.4", models.Build{BuildID: i},
+			fmt.Sprintf("%040d", i*3), fmt.Sprintf("%040d", i*3+1), fmt.Sprintf("%040d", i*3+2))
+	}
+
+	builds, _, err := NewBuildService(db).ListBuilds(project.ID, []int{project.Versions["1.21.4"]}, models.BuildListFilter{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return db, builds
+}
+
+// BenchmarkChangesPerBuild 旧实现：每个构建查询一次变更
+func BenchmarkChangesPerBuild(b *testing.B) {
+	db, builds := openBenchDB(b)
+	changes := NewChangeService(db)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, build := range builds {
+			if _, err := changes.GetChangesByIDs([]int64(build.Changes)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkChangesBatched 一次查询加载全部构建的变更
+func BenchmarkChangesBatched(b *testing.B) {
+	db, builds := openBenchDB(b)
+	changes := NewChangeService(db)
+
+	rowIDs := make([]int, len(builds))
+	for i, build := range builds {
+		rowIDs[i] = build.ID
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := changes.GetChangesByBuilds(rowIDs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
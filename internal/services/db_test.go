@@ -0,0 +1,93 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+	"webapi/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// openTestDB 连接环境变量 env 指向的已初始化数据库，未设置时跳过
+func openTestDB(tb testing.TB, env string) *sql.DB {
+	tb.Helper()
+
+	url := os.Getenv(env)
+	if url == "" {
+		tb.Skip(env + " is not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// testProject 为测试写入的临时项目，版本组 1.21 下有 1.21.3 与 1.21.4 两个版本
+type testProject struct {
+	ID       string
+	GroupID  int
+	Versions map[string]int
+}
+
+// newTestProject 创建临时项目，测试结束后删除其全部数据
+func newTestProject(tb testing.TB, db *sql.DB) *testProject {
+	tb.Helper()
+
+	project := &testProject{ID: fmt.Sprintf("test-%d", time.Now().UnixNano()), Versions: make(map[string]int)}
+	if _, err := db.Exec("INSERT INTO projects (id, name, repo) VALUES ($1, $1, '')", project.ID); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		for _, table := range []string{"download_stats", "downloads", "builds", "changes", "versions", "version_groups"} {
+			db.Exec("DELETE FROM "+table+" WHERE project = $1", project.ID)
+		}
+		db.Exec("DELETE FROM projects WHERE id = $1", project.ID)
+	})
+
+	if err := db.QueryRow("INSERT INTO version_groups (project, name) VALUES ($1, '1.21') RETURNING id", project.ID).Scan(&project.GroupID); err != nil {
+		tb.Fatal(err)
+	}
+	for _, name := range []string{"1.21.3", "1.21.4"} {
+		var versionID int
+		if err := db.QueryRow("INSERT INTO versions (name, project, version_group) VALUES ($1, $2, $3) RETURNING id", name, project.ID, project.GroupID).Scan(&versionID); err != nil {
+			tb.Fatal(err)
+		}
+		project.Versions[name] = versionID
+	}
+
+	return project
+}
+
+// addBuild 写入一个构建，commits 为该构建包含的提交哈希
+func (p *testProject) addBuild(tb testing.TB, db *sql.DB, version string, build models.Build, commits ...string) {
+	tb.Helper()
+
+	changes := make([]models.ChangeResponse, len(commits))
+	for i, commit := range commits {
+		changes[i] = models.ChangeResponse{Commit: commit, Summary: commit, Message: commit + "\n"}
+	}
+	ids, err := NewChangeService(db).InsertChanges(p.ID, changes)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if ids == nil {
+		ids = []int64{}
+	}
+	if build.Time.IsZero() {
+		build.Time = time.Now()
+	}
+
+	if _, err := db.Exec(`
+		INSERT INTO builds (project, build_id, time, experimental, jar_name, sha256, version, tag, changes, download_sources, private)
+		VALUES ($1, $2, $3, false, 'test.jar', $4, $5, $6, $7, '{application}', $8)
+	`, p.ID, build.BuildID, build.Time, build.SHA256, p.Versions[version], build.Tag, pq.Array(ids), build.Private); err != nil {
+		tb.Fatal(err)
+	}
+}
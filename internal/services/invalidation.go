@@ -0,0 +1,143 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+	"webapi/internal/logger"
+	"webapi/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// invalidationPingInterval 长时间没有通知时检查监听连接是否仍然可用
+const invalidationPingInterval = 90 * time.Second
+
+// InvalidationService 在写操作后清除本实例的响应缓存，并通过 Postgres NOTIFY 通知其他实例
+type InvalidationService struct {
+	db      *sql.DB
+	cache   *ResponseCache
+	channel string
+	origin  string
+
+	listener  *pq.Listener
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewInvalidationService 创建服务，channel 为空时只清除本实例的缓存
+func NewInvalidationService(db *sql.DB, databaseURL, channel string, cache *ResponseCache) *InvalidationService {
+	s := &InvalidationService{
+		db:      db,
+		cache:   cache,
+		channel: channel,
+		origin:  newInstanceID(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if channel == "" {
+		close(s.done)
+		return s
+	}
+
+	s.listener = pq.NewListener(databaseURL, time.Second, time.Minute, s.onListenerEvent)
+	if err := s.listener.Listen(channel); err != nil {
+		logger.Errorf("Failed to listen on %s: %v", channel, err)
+	}
+
+	go s.run()
+
+	return s
+}
+
+// Changed 清除本实例中受影响项目的缓存，并广播给其他实例
+func (s *InvalidationService) Changed(event models.ChangeEvent) {
+	s.cache.Invalidate(event.Project)
+
+	if s.channel == "" {
+		return
+	}
+
+	event.Origin = s.origin
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Errorf("Failed to encode change event: %v", err)
+		return
+	}
+	if _, err := s.db.Exec("SELECT pg_notify($1, $2)", s.channel, string(payload)); err != nil {
+		logger.Errorf("Failed to notify change of project %s: %v", event.Project, err)
+	}
+}
+
+// Close 停止监听
+func (s *InvalidationService) Close() {
+	s.closeOnce.Do(func() {
+		if s.listener == nil {
+			return
+		}
+		close(s.stop)
+		<-s.done
+		s.listener.Close()
+	})
+}
+
+func (s *InvalidationService) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(invalidationPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case notification := <-s.listener.Notify:
+			s.handle(notification)
+		case <-ticker.C:
+			go s.listener.Ping()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *InvalidationService) handle(notification *pq.Notification) {
+	// 重连后会收到 nil，断线期间可能错过了通知，只能清除全部缓存
+	if notification == nil {
+		logger.Warnf("Change notification listener reconnected, dropping all cached responses")
+		s.cache.InvalidateAll()
+		return
+	}
+
+	var event models.ChangeEvent
+	if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil || event.Project == "" {
+		logger.Warnf("Ignoring malformed change notification %q", notification.Extra)
+		return
+	}
+	if event.Origin == s.origin {
+		return
+	}
+
+	logger.Debugf("Invalidating cached responses of %s after change on another instance", event.Project)
+	s.cache.Invalidate(event.Project)
+}
+
+func (s *InvalidationService) onListenerEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		logger.Warnf("Change notification listener disconnected: %v", err)
+	case pq.ListenerEventConnectionAttemptFailed:
+		logger.Warnf("Change notification listener failed to reconnect: %v", err)
+	}
+}
+
+func newInstanceID() string {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return time.Now().Format(time.RFC3339Nano)
+	}
+	return hex.EncodeToString(raw)
+}
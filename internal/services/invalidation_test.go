@@ -0,0 +1,56 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/models"
+
+	"github.com/lib/pq"
+)
+
+func TestInvalidationNotifications(t *testing.T) {
+	cache := NewResponseCache(config.CacheConfig{TTL: time.Minute, MaxSize: 1 << 20}, nil)
+	s := NewInvalidationService(nil, "", "", cache)
+	defer s.Close()
+
+	body := models.CachedResponse{Body: []byte(`{}`)}
+	fill := func() {
+		for _, project := range []string{"mint", "leaves"} {
+			_, generation, _ := cache.Get(project, "/v2/projects/"+project)
+			cache.Set(project, "/v2/projects/"+project, generation, body)
+		}
+	}
+	cached := func(project string) bool {
+		_, _, ok := cache.Get(project, "/v2/projects/"+project)
+		return ok
+	}
+	notify := func(event models.ChangeEvent) {
+		payload, _ := json.Marshal(event)
+		s.handle(&pq.Notification{Channel: "webapi_changes", Extra: string(payload)})
+	}
+
+	fill()
+	notify(models.ChangeEvent{Project: "mint", Version: "1.21.4", Build: 12, Origin: "other-instance"})
+	if cached("mint") || !cached("leaves") {
+		t.Error("expected a remote change to invalidate only the affected project")
+	}
+
+	fill()
+	notify(models.ChangeEvent{Project: "mint", Origin: s.origin})
+	if !cached("mint") {
+		t.Error("expected the instance's own notification to be ignored")
+	}
+
+	// 重连后收到 nil，清除全部缓存，且重连前开始的请求不能写回
+	_, stale, _ := cache.Get("paper", "/v2/projects/paper")
+	s.handle(nil)
+	if cached("mint") || cached("leaves") {
+		t.Error("expected a reconnect to drop all cached responses")
+	}
+	cache.Set("paper", "/v2/projects/paper", stale, body)
+	if cached("paper") {
+		t.Error("expected a response loaded before the reconnect to be dropped")
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"webapi/internal/models"
+)
+
+// 以下测试需要 WEBAPI_TEST_DB_URL 指向已初始化的数据库
+
+func TestPublicBuildQueriesSkipPrivateBuilds(t *testing.T) {
+	db := openTestDB(t, "WEBAPI_TEST_DB_URL")
+	project := newTestProject(t, db)
+	versionID := project.Versions["1.21.4"]
+
+	project.addBuild(t, db, "1.21.4", models.Build{BuildID: 1, Tag: "1"}, "aaaa1111")
+	project.addBuild(t, db, "1.21.4", models.Build{BuildID: 2, Tag: "2", Private: true}, "bbbb2222")
+
+	builds := NewBuildService(db)
+	buildIDs := func(list []models.Build) []int {
+		ids := []int{}
+		for _, build := range list {
+			ids = append(ids, build.BuildID)
+		}
+		return ids
+	}
+
+	byVersion, err := builds.GetBuildsByVersion(project.ID, versionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := buildIDs(byVersion); !reflect.DeepEqual(ids, []int{1}) {
+		t.Errorf("GetBuildsByVersion: expected only public build 1, got %v", ids)
+	}
+
+	listed, _, err := builds.ListBuilds(project.ID, []int{versionID}, models.BuildListFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := buildIDs(listed); !reflect.DeepEqual(ids, []int{1}) {
+		t.Errorf("ListBuilds: expected only public build 1, got %v", ids)
+	}
+
+	if latest, err := builds.ParseBuildID(project.ID, versionID, "latest"); err != nil || latest != 1 {
+		t.Errorf("ParseBuildID(latest): expected 1, got %d %v", latest, err)
+	}
+	if latest, err := NewVersionService(db).GetLatestBuildID(project.ID, []int{versionID}); err != nil || latest != 1 {
+		t.Errorf("GetLatestBuildID: expected 1, got %d %v", latest, err)
+	}
+
+	changeID, err := NewChangeService(db).GetChangeIDByCommitPrefix(project.ID, "bbbb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewChangeService(db).GetBuildIDByChange(versionID, changeID); err == nil {
+		t.Error("GetBuildIDByChange: expected the private build to be skipped")
+	}
+
+	// 签名下载仍需读取私有构建
+	if build, err := builds.GetBuild(project.ID, versionID, 2); err != nil || !build.Private {
+		t.Errorf("GetBuild: expected private build 2, got %+v %v", build, err)
+	}
+}
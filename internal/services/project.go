@@ -2,6 +2,8 @@ package services
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 	"webapi/internal/models"
 )
 
@@ -14,7 +16,7 @@ func NewProjectService(db *sql.DB) *ProjectService {
 }
 
 func (s *ProjectService) GetAll() ([]models.Project, error) {
-	rows, err := s.db.Query("SELECT id, name, repo FROM projects")
+	rows, err := s.db.Query("SELECT id, name, repo, version_scheme, auto_provision, version_group_rule FROM projects")
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +25,7 @@ func (s *ProjectService) GetAll() ([]models.Project, error) {
 	var projects []models.Project
 	for rows.Next() {
 		var project models.Project
-		if err := rows.Scan(&project.ID, &project.Name, &project.Repo); err != nil {
+		if err := rows.Scan(&project.ID, &project.Name, &project.Repo, &project.VersionScheme, &project.AutoProvision, &project.VersionGroupRule); err != nil {
 			return nil, err
 		}
 		projects = append(projects, project)
@@ -34,8 +36,8 @@ func (s *ProjectService) GetAll() ([]models.Project, error) {
 
 func (s *ProjectService) GetByID(projectID string) (*models.Project, error) {
 	var project models.Project
-	err := s.db.QueryRow("SELECT id, name, repo FROM projects WHERE id = $1", projectID).
-		Scan(&project.ID, &project.Name, &project.Repo)
+	err := s.db.QueryRow("SELECT id, name, repo, version_scheme, auto_provision, version_group_rule FROM projects WHERE id = $1", projectID).
+		Scan(&project.ID, &project.Name, &project.Repo, &project.VersionScheme, &project.AutoProvision, &project.VersionGroupRule)
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -47,50 +49,113 @@ func (s *ProjectService) GetByID(projectID string) (*models.Project, error) {
 	return &project, nil
 }
 
+// GetVersions 返回项目的全部版本，按项目的版本号规则从新到旧排列
 func (s *ProjectService) GetVersions(projectID string) ([]string, error) {
-	rows, err := s.db.Query(`
-		SELECT DISTINCT v.name 
-		FROM versions v 
-		WHERE v.project = $1 
-		ORDER BY v.name DESC
+	_, versions, err := queryOrderedVersions(s.db, `
+		SELECT v.id, v.name, p.version_scheme
+		FROM versions v
+		JOIN projects p ON p.id = v.project
+		WHERE v.project = $1
+		ORDER BY v.id
 	`, projectID)
-	if err != nil {
-		return nil, err
+	return versions, err
+}
+
+// GetVersionGroups 返回项目的全部版本组，按项目的版本号规则从新到旧排列
+func (s *ProjectService) GetVersionGroups(projectID string) ([]string, error) {
+	_, versionGroups, err := queryOrderedVersions(s.db, `
+		SELECT vg.id, vg.name, p.version_scheme
+		FROM version_groups vg
+		JOIN projects p ON p.id = vg.project
+		WHERE vg.project = $1
+		ORDER BY vg.id
+	`, projectID)
+	return versionGroups, err
+}
+
+// GetLatestVersion 返回按版本号规则最新的版本，项目没有版本时返回空字符串
+func (s *ProjectService) GetLatestVersion(projectID string) (string, error) {
+	versions, err := s.GetVersions(projectID)
+	if err != nil || len(versions) == 0 {
+		return "", err
 	}
-	defer rows.Close()
+	return versions[0], nil
+}
 
-	var versions []string
-	for rows.Next() {
-		var version string
-		if err := rows.Scan(&version); err != nil {
-			return nil, err
-		}
-		versions = append(versions, version)
+// LastModified 返回项目数据的最后修改时间，projectID 为空时返回所有项目中最新的时间
+// 项目不存在时返回零值
+func (s *ProjectService) LastModified(projectID string) (time.Time, error) {
+	var modified sql.NullTime
+	var err error
+	if projectID == "" {
+		err = s.db.QueryRow("SELECT MAX(updated_at) FROM projects").Scan(&modified)
+	} else {
+		err = s.db.QueryRow("SELECT updated_at FROM projects WHERE id = $1", projectID).Scan(&modified)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return time.Time{}, err
 	}
 
-	return versions, nil
+	return modified.Time, nil
 }
 
-func (s *ProjectService) GetVersionGroups(projectID string) ([]string, error) {
-	rows, err := s.db.Query(`
-		SELECT DISTINCT vg.name 
-		FROM version_groups vg 
-		WHERE vg.project = $1 
-		ORDER BY vg.name DESC
-	`, projectID)
+// MarkModified 在项目的构建或下载源变更后更新其修改时间，使缓存的 ETag 失效
+func (s *ProjectService) MarkModified(projectID string) error {
+	_, err := s.db.Exec("UPDATE projects SET updated_at = now() WHERE id = $1", projectID)
+	return err
+}
+
+// Create 创建项目
+func (s *ProjectService) Create(project models.Project) error {
+	_, err := s.db.Exec(`
+		INSERT INTO projects (id, name, repo, version_scheme, auto_provision, version_group_rule)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, project.ID, project.Name, project.Repo, project.VersionScheme, project.AutoProvision, project.VersionGroupRule)
+	return err
+}
+
+// Update 修改项目的名称、仓库、版本号规则与自动创建版本的设置
+func (s *ProjectService) Update(project models.Project) error {
+	_, err := s.db.Exec(`
+		UPDATE projects
+		SET name = $2, repo = $3, version_scheme = $4, auto_provision = $5, version_group_rule = $6
+		WHERE id = $1
+	`, project.ID, project.Name, project.Repo, project.VersionScheme, project.AutoProvision, project.VersionGroupRule)
+	return err
+}
+
+// Delete 删除没有构建的项目，连同其版本组、版本、变更、下载源与下载统计
+func (s *ProjectService) Delete(projectID string) error {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var versionGroups []string
-	for rows.Next() {
-		var versionGroup string
-		if err := rows.Scan(&versionGroup); err != nil {
-			return nil, err
+	var builds int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM builds WHERE project = $1", projectID).Scan(&builds); err != nil {
+		return err
+	}
+	if builds > 0 {
+		return fmt.Errorf("project %s still has %d builds", projectID, builds)
+	}
+
+	for _, table := range []string{"download_stats", "downloads", "changes", "versions", "version_groups", "projects"} {
+		column := "project"
+		if table == "projects" {
+			column = "id"
+		}
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = $1", table, column), projectID); err != nil {
+			return err
 		}
-		versionGroups = append(versionGroups, versionGroup)
 	}
 
-	return versionGroups, nil
-}
\ No newline at end of file
+	return tx.Commit()
+}
+
+// CountBuilds 返回项目的构建数量
+func (s *ProjectService) CountBuilds(projectID string) (int, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM builds WHERE project = $1", projectID).Scan(&count)
+	return count, err
+}
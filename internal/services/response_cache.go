@@ -0,0 +1,206 @@
+package services
+
+import (
+	"container/list"
+	"sync"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/models"
+)
+
+// ResponseCache 缓存查询接口的成功响应，按项目失效
+// 以 projectID 为空登记的条目（如项目列表）在任意项目失效时一并清除
+type ResponseCache struct {
+	ttl      time.Duration
+	maxSize  int64
+	projects *ProjectService
+
+	mu          sync.Mutex
+	entries     map[string]*list.Element
+	lru         *list.List
+	byProject   map[string]map[string]struct{}
+	generations map[string]uint64
+	modified    map[string]modifiedEntry
+	size        int64
+	// clock 每次失效递增；floor 为最近一次全部失效时的 clock
+	clock uint64
+	floor uint64
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type responseEntry struct {
+	key      string
+	project  string
+	response models.CachedResponse
+	expires  time.Time
+}
+
+type modifiedEntry struct {
+	modified time.Time
+	expires  time.Time
+}
+
+func NewResponseCache(cfg config.CacheConfig, projects *ProjectService) *ResponseCache {
+	return &ResponseCache{
+		ttl:         cfg.TTL,
+		maxSize:     cfg.MaxSize,
+		projects:    projects,
+		entries:     make(map[string]*list.Element),
+		lru:         list.New(),
+		byProject:   make(map[string]map[string]struct{}),
+		generations: make(map[string]uint64),
+		modified:    make(map[string]modifiedEntry),
+	}
+}
+
+// Enabled TTL 或容量为 0 时不缓存
+func (c *ResponseCache) Enabled() bool {
+	return c.ttl > 0 && c.maxSize > 0
+}
+
+// Get 查找缓存的响应，同时返回项目当前的版本号，未命中时需将其传给 Set
+func (c *ResponseCache) Get(project, key string) (models.CachedResponse, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	generation := c.generationLocked(project)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*responseEntry)
+		if time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(element)
+			c.hits++
+			return entry.response, generation, true
+		}
+		c.removeLocked(key)
+	}
+
+	c.misses++
+	return models.CachedResponse{}, generation, false
+}
+
+// Set 保存响应；若在 Get 之后项目已被失效，则丢弃这份可能过期的响应
+func (c *ResponseCache) Set(project, key string, generation uint64, response models.CachedResponse) {
+	size := int64(len(key) + len(response.Body))
+	if size > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generationLocked(project) != generation {
+		return
+	}
+
+	c.removeLocked(key)
+	c.entries[key] = c.lru.PushFront(&responseEntry{
+		key:      key,
+		project:  project,
+		response: response,
+		expires:  time.Now().Add(c.ttl),
+	})
+	if c.byProject[project] == nil {
+		c.byProject[project] = make(map[string]struct{})
+	}
+	c.byProject[project][key] = struct{}{}
+	c.size += size
+
+	for c.size > c.maxSize && c.lru.Len() > 0 {
+		c.removeLocked(c.lru.Back().Value.(*responseEntry).key)
+		c.evictions++
+	}
+}
+
+// Invalidate 清除项目的全部缓存，以及依赖所有项目的缓存
+func (c *ResponseCache) Invalidate(project string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, p := range []string{project, ""} {
+		c.clock++
+		c.generations[p] = c.clock
+		delete(c.modified, p)
+		for key := range c.byProject[p] {
+			c.removeLocked(key)
+		}
+	}
+}
+
+// InvalidateAll 清除全部缓存，用于无法确定错过了哪些变更的情况（如通知连接重连）
+func (c *ResponseCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.clock++
+	c.floor = c.clock
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.byProject = make(map[string]map[string]struct{})
+	c.modified = make(map[string]modifiedEntry)
+	c.size = 0
+}
+
+// LastModified 带缓存地返回项目的最后修改时间，供条件请求使用
+func (c *ResponseCache) LastModified(projectID string) (time.Time, error) {
+	if !c.Enabled() {
+		return c.projects.LastModified(projectID)
+	}
+
+	c.mu.Lock()
+	if entry, ok := c.modified[projectID]; ok && time.Now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.modified, nil
+	}
+	generation := c.generationLocked(projectID)
+	c.mu.Unlock()
+
+	modified, err := c.projects.LastModified(projectID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	c.mu.Lock()
+	if c.generationLocked(projectID) == generation {
+		c.modified[projectID] = modifiedEntry{modified: modified, expires: time.Now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+
+	return modified, nil
+}
+
+func (c *ResponseCache) Stats() models.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return models.CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.lru.Len(),
+		Size:      c.size,
+		MaxSize:   c.maxSize,
+	}
+}
+
+// generationLocked 返回项目当前的版本号，任何针对该项目的失效都会使其变化
+func (c *ResponseCache) generationLocked(project string) uint64 {
+	if generation := c.generations[project]; generation > c.floor {
+		return generation
+	}
+	return c.floor
+}
+
+func (c *ResponseCache) removeLocked(key string) {
+	element, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	entry := element.Value.(*responseEntry)
+	c.size -= int64(len(entry.key) + len(entry.response.Body))
+	c.lru.Remove(element)
+	delete(c.entries, key)
+	delete(c.byProject[entry.project], key)
+}
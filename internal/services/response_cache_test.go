@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/models"
+)
+
+func TestResponseCacheInvalidation(t *testing.T) {
+	cache := NewResponseCache(config.CacheConfig{TTL: time.Minute, MaxSize: 1 << 20}, nil)
+	body := models.CachedResponse{ContentType: "application/json", Body: []byte(`{"code":200}`)}
+
+	_, generation, ok := cache.Get("mint", "/v2/projects/mint")
+	if ok {
+		t.Fatal("expected an empty cache to miss")
+	}
+	cache.Set("mint", "/v2/projects/mint", generation, body)
+	_, listGeneration, _ := cache.Get("", "/v2/projects")
+	cache.Set("", "/v2/projects", listGeneration, body)
+	_, leavesGeneration, _ := cache.Get("leaves", "/v2/projects/leaves")
+	cache.Set("leaves", "/v2/projects/leaves", leavesGeneration, body)
+
+	if _, _, ok := cache.Get("mint", "/v2/projects/mint"); !ok {
+		t.Fatal("expected a cached response to hit")
+	}
+
+	cache.Invalidate("mint")
+	if _, _, ok := cache.Get("mint", "/v2/projects/mint"); ok {
+		t.Error("expected mint to be invalidated")
+	}
+	if _, _, ok := cache.Get("", "/v2/projects"); ok {
+		t.Error("expected the project list to be invalidated with mint")
+	}
+	if _, _, ok := cache.Get("leaves", "/v2/projects/leaves"); !ok {
+		t.Error("expected leaves to stay cached")
+	}
+
+	// 失效前开始的请求不能写回过期的响应
+	_, stale, _ := cache.Get("mint", "/v2/projects/mint")
+	cache.Invalidate("mint")
+	cache.Set("mint", "/v2/projects/mint", stale, body)
+	if _, _, ok := cache.Get("mint", "/v2/projects/mint"); ok {
+		t.Error("expected a response loaded before invalidation to be dropped")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Entries != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestResponseCacheLimits(t *testing.T) {
+	cache := NewResponseCache(config.CacheConfig{TTL: time.Minute, MaxSize: 64}, nil)
+	body := models.CachedResponse{Body: make([]byte, 20)}
+
+	for _, key := range []string{"/a", "/b", "/c"} {
+		_, generation, _ := cache.Get("mint", key)
+		cache.Set("mint", key, generation, body)
+	}
+	if _, _, ok := cache.Get("mint", "/a"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if stats := cache.Stats(); stats.Size > 64 || stats.Evictions != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	expiring := NewResponseCache(config.CacheConfig{TTL: time.Millisecond, MaxSize: 1 << 20}, nil)
+	_, generation, _ := expiring.Get("mint", "/a")
+	expiring.Set("mint", "/a", generation, body)
+	time.Sleep(5 * time.Millisecond)
+	if _, _, ok := expiring.Get("mint", "/a"); ok {
+		t.Error("expected an expired entry to miss")
+	}
+}
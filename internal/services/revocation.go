@@ -0,0 +1,176 @@
+package services
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+	"webapi/internal/logger"
+	"webapi/internal/models"
+)
+
+// RevocationService 维护已吊销 token 的 jti 列表，鉴权时只读取内存缓存
+type RevocationService struct {
+	db              *sql.DB
+	refreshInterval time.Duration
+
+	mu      sync.RWMutex
+	revoked map[string]struct{}
+	// recent 本实例吊销的 jti 及其写入时间，刷新时保留加载开始后才写入的条目
+	recent map[string]time.Time
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func NewRevocationService(db *sql.DB, refreshInterval time.Duration) *RevocationService {
+	s := &RevocationService{
+		db:              db,
+		refreshInterval: refreshInterval,
+		revoked:         make(map[string]struct{}),
+		recent:          make(map[string]time.Time),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	if s.refreshInterval <= 0 {
+		s.refreshInterval = 30 * time.Second
+	}
+
+	if err := s.Refresh(); err != nil {
+		logger.Errorf("Failed to load revoked tokens: %v", err)
+	}
+
+	go s.run()
+
+	return s
+}
+
+// IsRevoked 判断 jti 是否已被吊销
+func (s *RevocationService) IsRevoked(jti string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.revoked[jti]
+	return ok
+}
+
+// Refresh 从数据库重新加载仍在有效期内的吊销记录
+func (s *RevocationService) Refresh() error {
+	started := time.Now()
+	rows, err := s.db.Query(`
+		SELECT jti FROM revoked_tokens
+		WHERE expires_at IS NULL OR expires_at > now()
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	revoked := make(map[string]struct{})
+	for rows.Next() {
+		var jti string
+		if err := rows.Scan(&jti); err != nil {
+			return err
+		}
+		revoked[jti] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.replace(revoked, started)
+
+	return nil
+}
+
+// replace 以 started 时开始加载的 revoked 替换缓存
+// 在此之前写入的吊销已包含在加载结果中，之后写入的可能未被读到，需要保留
+func (s *RevocationService) replace(revoked map[string]struct{}, started time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for jti, at := range s.recent {
+		if at.Before(started) {
+			delete(s.recent, jti)
+			continue
+		}
+		revoked[jti] = struct{}{}
+	}
+	s.revoked = revoked
+}
+
+// Revoke 吊销 token，立即生效于当前实例，其他实例在下次刷新时生效
+func (s *RevocationService) Revoke(req models.RevokeTokenRequest) error {
+	var expiresAt interface{}
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO revoked_tokens (jti, reason, revoked_at, expires_at)
+		VALUES ($1, $2, now(), $3)
+		ON CONFLICT (jti) DO UPDATE SET reason = EXCLUDED.reason, expires_at = EXCLUDED.expires_at
+	`, req.JTI, req.Reason, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.revoked[req.JTI] = struct{}{}
+	s.recent[req.JTI] = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *RevocationService) GetAll() ([]models.RevokedToken, error) {
+	rows, err := s.db.Query(`
+		SELECT jti, reason, revoked_at, expires_at
+		FROM revoked_tokens
+		ORDER BY revoked_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.RevokedToken{}
+	for rows.Next() {
+		var token models.RevokedToken
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&token.JTI, &token.Reason, &token.RevokedAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			token.ExpiresAt = &expiresAt.Time
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Close 停止后台刷新
+func (s *RevocationService) Close() {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+func (s *RevocationService) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Refresh(); err != nil {
+				logger.Errorf("Failed to refresh revoked tokens: %v", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
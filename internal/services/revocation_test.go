@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevocationRefreshKeepsConcurrentRevocations(t *testing.T) {
+	s := &RevocationService{revoked: make(map[string]struct{}), recent: make(map[string]time.Time)}
+
+	started := time.Now()
+	s.revoked["before"] = struct{}{}
+	s.recent["before"] = started.Add(-time.Second)
+	// 加载进行中时吊销的 token 不在加载结果中
+	s.revoked["during"] = struct{}{}
+	s.recent["during"] = started.Add(time.Millisecond)
+
+	s.replace(map[string]struct{}{"before": {}, "other": {}}, started)
+
+	for _, jti := range []string{"before", "during", "other"} {
+		if !s.IsRevoked(jti) {
+			t.Errorf("expected %s to stay revoked after the refresh", jti)
+		}
+	}
+	if _, ok := s.recent["before"]; ok {
+		t.Error("expected revocations covered by the load to be dropped from the recent set")
+	}
+
+	// 下一次刷新已能读到 during
+	s.replace(map[string]struct{}{"during": {}}, started.Add(time.Second))
+	if !s.IsRevoked("during") || s.IsRevoked("before") || len(s.recent) != 0 {
+		t.Errorf("expected the cache to follow the database once it has caught up, got %v %v", s.revoked, s.recent)
+	}
+}
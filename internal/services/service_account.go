@@ -0,0 +1,145 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"webapi/internal/logger"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/lib/pq"
+)
+
+// serviceAccountTouchInterval last_used_at 的最小更新间隔，避免每次请求都写库
+const serviceAccountTouchInterval = time.Minute
+
+const serviceAccountColumns = "id, name, key_prefix, projects, scopes, created_at, rotated_at, last_used_at, expires_at"
+
+type ServiceAccountService struct {
+	db *sql.DB
+}
+
+func NewServiceAccountService(db *sql.DB) *ServiceAccountService {
+	return &ServiceAccountService{db: db}
+}
+
+// Create 创建服务账号，返回只展示一次的明文 API key
+func (s *ServiceAccountService) Create(name string, projects, scopes []string, expiresAt *time.Time) (*models.ServiceAccount, string, error) {
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	row := s.db.QueryRow(`
+		INSERT INTO service_accounts (name, key_prefix, key_hash, projects, scopes, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+serviceAccountColumns,
+		name, prefix, hash, pq.Array(projects), pq.Array(scopes), time.Now(), expiresAt)
+
+	account, err := scanServiceAccount(row)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, "", fmt.Errorf("service account %s already exists", name)
+		}
+		return nil, "", err
+	}
+
+	return account, key, nil
+}
+
+// Rotate 为服务账号生成新的 API key，旧 key 立即失效
+// expiresAt 为 nil 时保留原有的过期时间
+func (s *ServiceAccountService) Rotate(name string, expiresAt *time.Time) (*models.ServiceAccount, string, error) {
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	row := s.db.QueryRow(`
+		UPDATE service_accounts
+		SET key_prefix = $2, key_hash = $3, rotated_at = $4, expires_at = COALESCE($5, expires_at)
+		WHERE name = $1
+		RETURNING `+serviceAccountColumns,
+		name, prefix, hash, time.Now(), expiresAt)
+
+	account, err := scanServiceAccount(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", fmt.Errorf("service account %s not found", name)
+		}
+		return nil, "", err
+	}
+
+	return account, key, nil
+}
+
+func (s *ServiceAccountService) GetAll() ([]models.ServiceAccount, error) {
+	rows, err := s.db.Query("SELECT " + serviceAccountColumns + " FROM service_accounts ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []models.ServiceAccount{}
+	for rows.Next() {
+		account, err := scanServiceAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// Authenticate 按 API key 查找未过期的服务账号，找不到时返回 nil
+func (s *ServiceAccountService) Authenticate(key string) (*models.ServiceAccount, error) {
+	row := s.db.QueryRow(`
+		SELECT `+serviceAccountColumns+` FROM service_accounts
+		WHERE key_hash = $1 AND (expires_at IS NULL OR expires_at > now())
+	`, utils.HashAPIKey(key))
+
+	account, err := scanServiceAccount(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if account.LastUsedAt == nil || now.Sub(*account.LastUsedAt) >= serviceAccountTouchInterval {
+		if _, err := s.db.Exec("UPDATE service_accounts SET last_used_at = $2 WHERE id = $1", account.ID, now); err != nil {
+			logger.Warnf("Failed to update last_used_at for service account %s: %v", account.Name, err)
+		}
+	}
+
+	return account, nil
+}
+
+func scanServiceAccount(row rowScanner) (*models.ServiceAccount, error) {
+	var account models.ServiceAccount
+	var projects, scopes pq.StringArray
+	var rotatedAt, lastUsedAt, expiresAt sql.NullTime
+
+	if err := row.Scan(&account.ID, &account.Name, &account.KeyPrefix, &projects, &scopes,
+		&account.CreatedAt, &rotatedAt, &lastUsedAt, &expiresAt); err != nil {
+		return nil, err
+	}
+
+	account.Projects = []string(projects)
+	account.Scopes = []string(scopes)
+	account.RotatedAt = nullTimePtr(rotatedAt)
+	account.LastUsedAt = nullTimePtr(lastUsedAt)
+	account.ExpiresAt = nullTimePtr(expiresAt)
+
+	return &account, nil
+}
+
+func nullTimePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
@@ -2,24 +2,55 @@ package services
 
 import (
 	"database/sql"
+	"webapi/internal/config"
 )
 
+// queryer 为 *sql.DB 与 *sql.Tx 共有的方法，使同一段 SQL 可以在事务内外执行
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 type Services struct {
-	Project     *ProjectService
-	Version     *VersionService
-	Build       *BuildService
-	Download    *DownloadService
-	Change      *ChangeService
+	Project      *ProjectService
+	Version      *VersionService
+	Build        *BuildService
+	Download     *DownloadService
+	Change       *ChangeService
 	VersionGroup *VersionGroupService
+	Stats        *StatsService
+	Artifacts    *ArtifactCache
+	Revocation   *RevocationService
+	Audit        *AuditService
+	Accounts     *ServiceAccountService
+	Responses    *ResponseCache
+	Invalidation *InvalidationService
 }
 
-func New(db *sql.DB) *Services {
+func New(cfg *config.Config, db *sql.DB) *Services {
+	project := NewProjectService(db)
+	responses := NewResponseCache(cfg.Cache, project)
+
 	return &Services{
-		Project:      NewProjectService(db),
+		Project:      project,
 		Version:      NewVersionService(db),
 		Build:        NewBuildService(db),
 		Download:     NewDownloadService(db),
 		Change:       NewChangeService(db),
 		VersionGroup: NewVersionGroupService(db),
+		Stats:        NewStatsService(db, cfg.Stats),
+		Artifacts:    NewArtifactCache(cfg.Download),
+		Revocation:   NewRevocationService(db, cfg.JWT.RevocationRefreshInterval),
+		Audit:        NewAuditService(db),
+		Accounts:     NewServiceAccountService(db),
+		Responses:    responses,
+		Invalidation: NewInvalidationService(db, cfg.Database.URL, cfg.Cache.NotifyChannel, responses),
 	}
+}
+
+// Close 释放后台任务持有的资源
+func (s *Services) Close() {
+	s.Stats.Close()
+	s.Revocation.Close()
+	s.Invalidation.Close()
 }
\ No newline at end of file
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/logger"
+	"webapi/internal/models"
+)
+
+// statsBucketSize 下载记录的时间粒度
+const statsBucketSize = time.Hour
+
+// statsQueueSize 待写入队列的容量，队列满时丢弃记录以保证下载重定向不被阻塞
+const statsQueueSize = 4096
+
+var statsIntervals = map[string]bool{
+	"hour":  true,
+	"day":   true,
+	"week":  true,
+	"month": true,
+}
+
+var statsGroupColumns = map[string]string{
+	"version":    "version",
+	"build":      "build_id::text",
+	"source":     "download_source",
+	"user_agent": "user_agent",
+}
+
+type StatsService struct {
+	db            *sql.DB
+	records       chan models.DownloadRecord
+	flushInterval time.Duration
+	batchSize     int
+	closeOnce     sync.Once
+	done          chan struct{}
+}
+
+func NewStatsService(db *sql.DB, cfg config.StatsConfig) *StatsService {
+	s := &StatsService{
+		db:            db,
+		records:       make(chan models.DownloadRecord, statsQueueSize),
+		flushInterval: cfg.FlushInterval,
+		batchSize:     cfg.BatchSize,
+		done:          make(chan struct{}),
+	}
+	if s.flushInterval <= 0 {
+		s.flushInterval = 10 * time.Second
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = 500
+	}
+
+	go s.run()
+
+	return s
+}
+
+// Record 异步记录一次下载，不会阻塞调用方
+func (s *StatsService) Record(record models.DownloadRecord) {
+	if record.Bucket.IsZero() {
+		record.Bucket = time.Now().UTC().Truncate(statsBucketSize)
+	}
+
+	select {
+	case s.records <- record:
+	default:
+		logger.Warnf("Download stats queue is full, dropping record for %s-%s#%d", record.Project, record.Version, record.BuildID)
+	}
+}
+
+// Close 停止后台写入并将剩余记录落库
+func (s *StatsService) Close() {
+	s.closeOnce.Do(func() {
+		close(s.records)
+		<-s.done
+	})
+}
+
+func (s *StatsService) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[models.DownloadRecord]int64)
+	size := 0
+
+	flush := func() {
+		if size == 0 {
+			return
+		}
+		if err := s.flush(pending); err != nil {
+			logger.Errorf("Failed to flush %d download records: %v", size, err)
+		}
+		pending = make(map[models.DownloadRecord]int64)
+		size = 0
+	}
+
+	for {
+		select {
+		case record, ok := <-s.records:
+			if !ok {
+				flush()
+				return
+			}
+			pending[record]++
+			size++
+			if size >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (s *StatsService) flush(pending map[models.DownloadRecord]int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO download_stats (project, version, build_id, download_source, bucket, user_agent, count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (project, version, build_id, download_source, bucket, user_agent)
+		DO UPDATE SET count = download_stats.count + EXCLUDED.count
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for record, count := range pending {
+		if _, err := stmt.Exec(
+			record.Project, record.Version, record.BuildID,
+			record.DownloadSource, record.Bucket, record.UserAgent, count,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// IsStatsInterval 判断 interval 是否为支持的统计时间粒度
+func IsStatsInterval(interval string) bool {
+	return statsIntervals[interval]
+}
+
+// IsStatsGroupBy 判断 groupBy 是否为支持的分组维度
+func IsStatsGroupBy(groupBy string) bool {
+	_, ok := statsGroupColumns[groupBy]
+	return ok
+}
+
+func (s *StatsService) GetTotal(filter models.DownloadStatsFilter) (int64, error) {
+	where, args := buildStatsWhere(filter)
+
+	var total int64
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(count), 0) FROM download_stats
+		WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (s *StatsService) GetSeries(filter models.DownloadStatsFilter, interval string) ([]models.DownloadStatsPoint, error) {
+	if !statsIntervals[interval] {
+		return nil, fmt.Errorf("invalid interval %s", interval)
+	}
+
+	where, args := buildStatsWhere(filter)
+	args = append(args, interval)
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT date_trunc($%d, bucket) AS t, SUM(count)
+		FROM download_stats
+		WHERE %s
+		GROUP BY t
+		ORDER BY t ASC
+	`, len(args), where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.DownloadStatsPoint{}
+	for rows.Next() {
+		var t time.Time
+		var point models.DownloadStatsPoint
+		if err := rows.Scan(&t, &point.Count); err != nil {
+			return nil, err
+		}
+		point.Time = t.UTC().Format("2006-01-02T15:04:05.000Z")
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
+
+func (s *StatsService) GetBreakdown(filter models.DownloadStatsFilter, groupBy string) ([]models.DownloadStatsGroup, error) {
+	column, ok := statsGroupColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid group_by %s", groupBy)
+	}
+
+	where, args := buildStatsWhere(filter)
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT %s AS k, SUM(count) AS c
+		FROM download_stats
+		WHERE %s
+		GROUP BY k
+		ORDER BY c DESC, k ASC
+	`, column, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.DownloadStatsGroup{}
+	for rows.Next() {
+		var group models.DownloadStatsGroup
+		if err := rows.Scan(&group.Key, &group.Count); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+func buildStatsWhere(filter models.DownloadStatsFilter) (string, []interface{}) {
+	conditions := []string{"project = $1"}
+	args := []interface{}{filter.Project}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Version != "" {
+		add("version = $%d", filter.Version)
+	}
+	if filter.BuildID != 0 {
+		add("build_id = $%d", filter.BuildID)
+	}
+	if filter.DownloadSource != "" {
+		add("download_source = $%d", filter.DownloadSource)
+	}
+	if !filter.Since.IsZero() {
+		add("bucket >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("bucket < $%d", filter.Until)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
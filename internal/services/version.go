@@ -3,6 +3,10 @@ package services
 import (
 	"database/sql"
 	"fmt"
+	"webapi/internal/models"
+	"webapi/internal/utils"
+
+	"github.com/lib/pq"
 )
 
 type VersionService struct {
@@ -30,6 +34,22 @@ func (s *VersionService) GetVersionID(projectID, versionName string) (int, error
 	return versionID, nil
 }
 
+func (s *VersionService) GetVersionName(versionID int) (string, error) {
+	var versionName string
+	err := s.db.QueryRow(`
+		SELECT name FROM versions WHERE id = $1
+	`, versionID).Scan(&versionName)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("version not found")
+		}
+		return "", err
+	}
+
+	return versionName, nil
+}
+
 func (s *VersionService) GetVersionGroupID(versionID int) (int, error) {
 	var versionGroupID int
 	err := s.db.QueryRow(`
@@ -63,12 +83,41 @@ func (s *VersionService) GetVersionGroupIDByName(projectID, versionGroupName str
 	return versionGroupID, nil
 }
 
+// GetVersionsByGroupID 返回版本组内的版本，按项目的版本号规则从新到旧排列
 func (s *VersionService) GetVersionsByGroupID(projectID string, versionGroupID int) ([]int, []string, error) {
-	rows, err := s.db.Query(`
-		SELECT id, name FROM versions 
-		WHERE project = $1 AND version_group = $2
-		ORDER BY name DESC
+	return queryOrderedVersions(s.db, `
+		SELECT v.id, v.name, p.version_scheme
+		FROM versions v
+		JOIN projects p ON p.id = v.project
+		WHERE v.project = $1 AND v.version_group = $2
+		ORDER BY v.id
 	`, projectID, versionGroupID)
+}
+
+// GetLatestBuildID 返回若干版本中最新的公开构建号，没有构建时为 0
+func (s *VersionService) GetLatestBuildID(projectID string, versionIDs []int) (int, error) {
+	if len(versionIDs) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		SELECT COALESCE(MAX(build_id), 0) 
+		FROM builds 
+		WHERE project = $1 AND version = ANY($2) AND NOT private
+	`
+	
+	var latestBuildID int
+	err := s.db.QueryRow(query, projectID, pq.Array(versionIDs)).Scan(&latestBuildID)
+	if err != nil {
+		return 0, err
+	}
+	
+	return latestBuildID, nil
+}
+
+// queryOrderedVersions 执行按创建顺序返回 id、名称与项目版本号规则的查询，并将结果排为从新到旧
+func queryOrderedVersions(db *sql.DB, query string, args ...interface{}) ([]int, []string, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -76,35 +125,225 @@ func (s *VersionService) GetVersionsByGroupID(projectID string, versionGroupID i
 
 	var ids []int
 	var names []string
+	scheme := utils.VersionSchemeMinecraft
 	for rows.Next() {
 		var id int
 		var name string
-		if err := rows.Scan(&id, &name); err != nil {
+		if err := rows.Scan(&id, &name, &scheme); err != nil {
 			return nil, nil, err
 		}
 		ids = append(ids, id)
 		names = append(names, name)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	err = utils.SortVersionsDescending(scheme, names, func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+	if err != nil {
+		return nil, nil, err
+	}
 
 	return ids, names, nil
 }
 
-func (s *VersionService) GetLatestBuildID(projectID string, versionIDs []int) (int, error) {
-	if len(versionIDs) == 0 {
-		return 0, nil
+// GetProjectMetadata 返回项目全部版本的元数据，按版本名索引
+func (s *VersionService) GetProjectMetadata(projectID string) (map[string]models.VersionMetadata, error) {
+	return s.queryMetadata(`
+		SELECT name, release_date, status, java_minimum, java_recommended, notice
+		FROM versions
+		WHERE project = $1
+	`, projectID)
+}
+
+// GetMetadataByIDs 返回指定版本的元数据，按版本名索引
+func (s *VersionService) GetMetadataByIDs(versionIDs []int) (map[string]models.VersionMetadata, error) {
+	return s.queryMetadata(`
+		SELECT name, release_date, status, java_minimum, java_recommended, notice
+		FROM versions
+		WHERE id = ANY($1)
+	`, pq.Array(versionIDs))
+}
+
+// GetMetadata 返回单个版本的元数据
+func (s *VersionService) GetMetadata(versionID int) (*models.VersionMetadata, error) {
+	metadata, err := s.GetMetadataByIDs([]int{versionID})
+	if err != nil {
+		return nil, err
 	}
+	for _, m := range metadata {
+		return &m, nil
+	}
+	return nil, fmt.Errorf("version not found")
+}
 
-	query := `
-		SELECT COALESCE(MAX(build_id), 0) 
-		FROM builds 
-		WHERE project = $1 AND version = ANY($2)
-	`
-	
-	var latestBuildID int
-	err := s.db.QueryRow(query, projectID, versionIDs).Scan(&latestBuildID)
+func (s *VersionService) queryMetadata(query string, args ...interface{}) (map[string]models.VersionMetadata, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	
-	return latestBuildID, nil
-}
\ No newline at end of file
+	defer rows.Close()
+
+	metadata := make(map[string]models.VersionMetadata)
+	for rows.Next() {
+		var name string
+		var releaseDate sql.NullTime
+		var javaMinimum, javaRecommended sql.NullInt64
+		var m models.VersionMetadata
+		if err := rows.Scan(&name, &releaseDate, &m.Status, &javaMinimum, &javaRecommended, &m.Notice); err != nil {
+			return nil, err
+		}
+		if releaseDate.Valid {
+			date := releaseDate.Time.Format("2006-01-02")
+			m.ReleaseDate = &date
+		}
+		m.Java.Minimum = nullIntPtr(javaMinimum)
+		m.Java.Recommended = nullIntPtr(javaRecommended)
+		metadata[name] = m
+	}
+
+	return metadata, rows.Err()
+}
+
+func nullIntPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	n := int(value.Int64)
+	return &n
+}
+
+// GetVersion 返回版本及其元数据，不存在时返回 nil
+func (s *VersionService) GetVersion(projectID, versionName string) (*models.Version, error) {
+	var version models.Version
+	var releaseDate sql.NullTime
+	var javaMinimum, javaRecommended sql.NullInt64
+	err := s.db.QueryRow(`
+		SELECT id, name, project, version_group, release_date, status, java_minimum, java_recommended, notice
+		FROM versions
+		WHERE project = $1 AND name = $2
+	`, projectID, versionName).Scan(&version.ID, &version.Name, &version.Project, &version.VersionGroup,
+		&releaseDate, &version.Status, &javaMinimum, &javaRecommended, &version.Notice)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if releaseDate.Valid {
+		date := releaseDate.Time.Format("2006-01-02")
+		version.ReleaseDate = &date
+	}
+	version.Java.Minimum = nullIntPtr(javaMinimum)
+	version.Java.Recommended = nullIntPtr(javaRecommended)
+
+	return &version, nil
+}
+
+// Create 创建版本并返回其 ID
+func (s *VersionService) Create(version models.Version) (int, error) {
+	var versionID int
+	err := s.db.QueryRow(`
+		INSERT INTO versions (name, project, version_group, release_date, status, java_minimum, java_recommended, notice)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, version.Name, version.Project, version.VersionGroup, version.ReleaseDate, version.Status,
+		version.Java.Minimum, version.Java.Recommended, version.Notice).Scan(&versionID)
+	return versionID, err
+}
+
+// Update 修改版本名称、所属版本组与元数据，改名时在同一事务中迁移该版本的下载统计
+func (s *VersionService) Update(version models.Version) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	if err := tx.QueryRow("SELECT name FROM versions WHERE id = $1 FOR UPDATE", version.ID).Scan(&oldName); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE versions
+		SET name = $2, version_group = $3, release_date = $4, status = $5,
+		    java_minimum = $6, java_recommended = $7, notice = $8
+		WHERE id = $1
+	`, version.ID, version.Name, version.VersionGroup, version.ReleaseDate, version.Status,
+		version.Java.Minimum, version.Java.Recommended, version.Notice); err != nil {
+		return err
+	}
+
+	if oldName != version.Name {
+		// 新名称可能残留已删除同名版本的统计，合并计数而不是直接改名
+		if _, err := tx.Exec(`
+			INSERT INTO download_stats (project, version, build_id, download_source, bucket, user_agent, count)
+			SELECT project, $3, build_id, download_source, bucket, user_agent, count
+			FROM download_stats
+			WHERE project = $1 AND version = $2
+			ON CONFLICT (project, version, build_id, download_source, bucket, user_agent)
+			DO UPDATE SET count = download_stats.count + EXCLUDED.count
+		`, version.Project, oldName, version.Name); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM download_stats WHERE project = $1 AND version = $2", version.Project, oldName); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete 删除没有构建的版本
+func (s *VersionService) Delete(versionID int) error {
+	result, err := s.db.Exec(`
+		DELETE FROM versions
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM builds WHERE version = $1)
+	`, versionID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("version still has builds")
+	}
+	return nil
+}
+
+// CountBuilds 返回版本的构建数量
+func (s *VersionService) CountBuilds(versionID int) (int, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM builds WHERE version = $1", versionID).Scan(&count)
+	return count, err
+}
+
+// MoveConflicts 返回将版本移入目标版本组后会与组内其他版本重复的构建号
+// 构建号在版本组内递增且唯一，存在重复时不能移动
+func (s *VersionService) MoveConflicts(versionID, versionGroupID int) ([]int, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT b.build_id
+		FROM builds b
+		JOIN versions v ON v.id = b.version
+		WHERE v.version_group = $2 AND v.id <> $1
+		  AND b.build_id IN (SELECT build_id FROM builds WHERE version = $1)
+		ORDER BY b.build_id
+	`, versionID, versionGroupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buildIDs []int
+	for rows.Next() {
+		var buildID int
+		if err := rows.Scan(&buildID); err != nil {
+			return nil, err
+		}
+		buildIDs = append(buildIDs, buildID)
+	}
+
+	return buildIDs, rows.Err()
+}
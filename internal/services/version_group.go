@@ -2,6 +2,7 @@ package services
 
 import (
 	"database/sql"
+	"fmt"
 )
 
 type VersionGroupService struct {
@@ -29,28 +30,44 @@ func (s *VersionGroupService) GetVersionGroupID(projectID, versionGroupName stri
 	return versionGroupID, nil
 }
 
+// GetVersionsByGroupID 返回版本组内的版本，按项目的版本号规则从新到旧排列
 func (s *VersionGroupService) GetVersionsByGroupID(projectID string, versionGroupID int) ([]int, []string, error) {
-	rows, err := s.db.Query(`
-		SELECT id, name FROM versions 
-		WHERE project = $1 AND version_group = $2
-		ORDER BY name DESC
+	return queryOrderedVersions(s.db, `
+		SELECT v.id, v.name, p.version_scheme
+		FROM versions v
+		JOIN projects p ON p.id = v.project
+		WHERE v.project = $1 AND v.version_group = $2
+		ORDER BY v.id
 	`, projectID, versionGroupID)
+}
+
+// Create 创建版本组并返回其 ID
+func (s *VersionGroupService) Create(projectID, name string) (int, error) {
+	var versionGroupID int
+	err := s.db.QueryRow(`
+		INSERT INTO version_groups (project, name) VALUES ($1, $2)
+		RETURNING id
+	`, projectID, name).Scan(&versionGroupID)
+	return versionGroupID, err
+}
+
+// Rename 修改版本组名称
+func (s *VersionGroupService) Rename(versionGroupID int, name string) error {
+	_, err := s.db.Exec("UPDATE version_groups SET name = $2 WHERE id = $1", versionGroupID, name)
+	return err
+}
+
+// Delete 删除不含任何版本的版本组
+func (s *VersionGroupService) Delete(versionGroupID int) error {
+	result, err := s.db.Exec(`
+		DELETE FROM version_groups
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM versions WHERE version_group = $1)
+	`, versionGroupID)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
-	defer rows.Close()
-
-	var ids []int
-	var names []string
-	for rows.Next() {
-		var id int
-		var name string
-		if err := rows.Scan(&id, &name); err != nil {
-			return nil, nil, err
-		}
-		ids = append(ids, id)
-		names = append(names, name)
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("version group still has versions")
 	}
-
-	return ids, names, nil
-}
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/models"
+)
+
+// 以下测试需要 WEBAPI_TEST_DB_URL 指向已初始化的数据库
+
+func TestUpdateRenamesDownloadStats(t *testing.T) {
+	db := openTestDB(t, "WEBAPI_TEST_DB_URL")
+	project := newTestProject(t, db)
+
+	bucket := time.Date(2024, 12, 3, 10, 0, 0, 0, time.UTC)
+	for _, version := range []string{"1.21.4", "1.21.4", "1.21.5"} {
+		if _, err := db.Exec(`
+			INSERT INTO download_stats (project, version, build_id, download_source, bucket, user_agent, count)
+			VALUES ($1, $2, 1, 'application', $3, '', 1)
+			ON CONFLICT (project, version, build_id, download_source, bucket, user_agent)
+			DO UPDATE SET count = download_stats.count + EXCLUDED.count
+		`, project.ID, version, bucket); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions := NewVersionService(db)
+	version, err := versions.GetVersion(project.ID, "1.21.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1.21.5 残留了已删除同名版本的统计
+	version.Name = "1.21.5"
+	if err := versions.Update(*version); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := NewStatsService(db, config.StatsConfig{})
+	defer stats.Close()
+	for version, expected := range map[string]int64{"1.21.4": 0, "1.21.5": 3} {
+		total, err := stats.GetTotal(models.DownloadStatsFilter{Project: project.ID, Version: version})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != expected {
+			t.Errorf("expected %d downloads for %s after the rename, got %d", expected, version, total)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix 服务账号 API key 的固定前缀，用于与 JWT 区分
+const APIKeyPrefix = "wak_"
+
+// apiKeyDisplayLength 保存在数据库中用于识别 key 的明文前缀长度
+const apiKeyDisplayLength = 12
+
+// GenerateAPIKey 生成新的 API key，返回明文 key、可公开展示的前缀与其哈希
+// 明文 key 只在生成时返回一次，数据库中仅保存哈希
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", "", err
+	}
+
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return key, key[:apiKeyDisplayLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey 计算 API key 的 SHA-256，key 本身为 256 位随机数，无需加盐慢哈希
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey 判断凭据是否为服务账号 API key
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
@@ -0,0 +1,31 @@
+package utils
+
+import "testing"
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsAPIKey(key) {
+		t.Errorf("expected %s to be recognised as an API key", key)
+	}
+	if IsAPIKey("eyJhbGciOiJFUzI1NiJ9.e30.sig") {
+		t.Error("expected a JWT not to be recognised as an API key")
+	}
+	if key[:len(prefix)] != prefix {
+		t.Errorf("expected prefix %s to be the start of the key", prefix)
+	}
+	if HashAPIKey(key) != hash {
+		t.Error("expected hash to match the generated key")
+	}
+
+	other, _, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key {
+		t.Error("expected generated keys to be unique")
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"webapi/internal/models"
+)
+
+// EncodeBuildCursor 将分页位置编码为不透明的 cursor 字符串
+func EncodeBuildCursor(cursor models.BuildCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", cursor.BuildID, cursor.ID)))
+}
+
+// DecodeBuildCursor 解析 EncodeBuildCursor 生成的 cursor
+func DecodeBuildCursor(value string) (*models.BuildCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor models.BuildCursor
+	if n, err := fmt.Sscanf(string(raw), "%d:%d", &cursor.BuildID, &cursor.ID); err != nil || n != 2 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &cursor, nil
+}
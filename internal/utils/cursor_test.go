@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+	"webapi/internal/models"
+)
+
+func TestBuildCursor(t *testing.T) {
+	cursor := models.BuildCursor{BuildID: 128, ID: 4096}
+
+	decoded, err := DecodeBuildCursor(EncodeBuildCursor(cursor))
+	if err != nil {
+		t.Fatalf("DecodeBuildCursor: %v", err)
+	}
+	if *decoded != cursor {
+		t.Errorf("expected %+v, got %+v", cursor, *decoded)
+	}
+
+	for _, value := range []string{"", "not a cursor", EncodeBuildCursor(cursor) + "!"} {
+		if _, err := DecodeBuildCursor(value); err == nil {
+			t.Errorf("expected DecodeBuildCursor(%q) to fail", value)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// DigestHeaders 根据记录的十六进制校验和生成 Digest (RFC 3230) 与 Repr-Digest (RFC 9530) 头的值
+func DigestHeaders(sha256Hex, sha512Hex string) (digest string, reprDigest string) {
+	var digests, reprDigests []string
+
+	add := func(algorithm, hexValue string) {
+		raw, err := hex.DecodeString(hexValue)
+		if hexValue == "" || err != nil {
+			return
+		}
+		encoded := base64.StdEncoding.EncodeToString(raw)
+		digests = append(digests, algorithm+"="+encoded)
+		reprDigests = append(reprDigests, algorithm+"=:"+encoded+":")
+	}
+
+	add("sha-256", sha256Hex)
+	add("sha-512", sha512Hex)
+
+	return strings.Join(digests, ","), strings.Join(reprDigests, ", ")
+}
+
+// IsSHA256Hex 判断字符串是否为 64 位十六进制的 sha256 值
+func IsSHA256Hex(value string) bool {
+	if len(value) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
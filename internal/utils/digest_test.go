@@ -0,0 +1,21 @@
+package utils
+
+import "testing"
+
+func TestDigestHeaders(t *testing.T) {
+	// sha256("hello")
+	sha256Hex := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	digest, reprDigest := DigestHeaders(sha256Hex, "")
+	if want := "sha-256=LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="; digest != want {
+		t.Errorf("digest = %q, want %q", digest, want)
+	}
+	if want := "sha-256=:LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=:"; reprDigest != want {
+		t.Errorf("repr digest = %q, want %q", reprDigest, want)
+	}
+
+	digest, reprDigest = DigestHeaders("not-hex", "")
+	if digest != "" || reprDigest != "" {
+		t.Errorf("expected invalid checksum to be skipped, got %q / %q", digest, reprDigest)
+	}
+}
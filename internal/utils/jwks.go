@@ -0,0 +1,441 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"webapi/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// VerificationKey 一个受信任的签名公钥及其有效期
+type VerificationKey struct {
+	KID       string
+	Algorithm string
+	Key       crypto.PublicKey
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// Active 判断公钥在 now 时刻是否处于有效期内
+func (k VerificationKey) Active(now time.Time) bool {
+	if !k.NotBefore.IsZero() && now.Before(k.NotBefore) {
+		return false
+	}
+	if !k.NotAfter.IsZero() && !now.Before(k.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// KeySet 启动时加载的全部受信任公钥，根据 token 头中的 kid 选择
+type KeySet struct {
+	keys []VerificationKey
+}
+
+// JWK 表示 JSON Web Key，nbf/exp 为本服务扩展的有效期字段（Unix 秒）
+type JWK struct {
+	Kty       string `json:"kty"`
+	Kid       string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Alg       string `json:"alg,omitempty"`
+	Crv       string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	NotAfter  int64  `json:"exp,omitempty"`
+}
+
+// JWKS 表示 JSON Web Key Set 文档
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeySet 从 API_PUBLIC_KEY 与 API_JWKS_PATH 加载全部受信任公钥
+func LoadKeySet(jwtConfig config.JWTConfig) (*KeySet, error) {
+	set := &KeySet{}
+
+	if jwtConfig.PublicKey != "" {
+		publicKey, err := parsePublicKey(jwtConfig.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse API_PUBLIC_KEY: %w", err)
+		}
+		kid, err := keyThumbprint(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		set.keys = append(set.keys, VerificationKey{
+			KID:       kid,
+			Algorithm: jwtConfig.Algorithm,
+			Key:       publicKey,
+		})
+	}
+
+	if jwtConfig.JWKSPath != "" {
+		keys, err := loadJWKSPath(jwtConfig.JWKSPath, jwtConfig.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWKS from %s: %w", jwtConfig.JWKSPath, err)
+		}
+		set.keys = append(set.keys, keys...)
+	}
+
+	return NewKeySet(set.keys)
+}
+
+// NewKeySet 校验 kid 唯一且公钥与算法匹配后创建 KeySet
+func NewKeySet(keys []VerificationKey) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no verification keys configured")
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if seen[key.KID] {
+			return nil, fmt.Errorf("duplicate key id %s", key.KID)
+		}
+		seen[key.KID] = true
+
+		if err := validateKeyAlgorithm(key.Algorithm, key.Key); err != nil {
+			return nil, fmt.Errorf("key %s: %w", key.KID, err)
+		}
+	}
+
+	return &KeySet{keys: keys}, nil
+}
+
+func (s *KeySet) hasKeyID(kid string) bool {
+	for _, key := range s.keys {
+		if key.KID == kid {
+			return true
+		}
+	}
+	return false
+}
+
+// Keyfunc 返回供 jwt.Parse 使用的公钥选择函数
+// 带 kid 的 token 只使用对应的公钥，不带 kid 的 token 依次尝试所有有效公钥
+func (s *KeySet) Keyfunc(now time.Time) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		alg := token.Method.Alg()
+
+		if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+			for _, key := range s.keys {
+				if key.KID != kid {
+					continue
+				}
+				if !key.Active(now) {
+					return nil, fmt.Errorf("key %s is not active", kid)
+				}
+				if key.Algorithm != alg {
+					return nil, fmt.Errorf("unexpected signing method %s for key %s", alg, kid)
+				}
+				return key.Key, nil
+			}
+			return nil, fmt.Errorf("unknown key id %s", kid)
+		}
+
+		var candidates []jwt.VerificationKey
+		for _, key := range s.keys {
+			if key.Active(now) && key.Algorithm == alg {
+				candidates = append(candidates, key.Key)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("unexpected signing method: %v", alg)
+		}
+		return jwt.VerificationKeySet{Keys: candidates}, nil
+	}
+}
+
+// KeyID 查找与 publicKey 相同的受信任公钥，返回其 kid
+func (s *KeySet) KeyID(publicKey crypto.PublicKey) (string, bool) {
+	thumbprint, err := keyThumbprint(publicKey)
+	if err != nil {
+		return "", false
+	}
+	for _, key := range s.keys {
+		if keyThumbprintOrEmpty(key.Key) == thumbprint {
+			return key.KID, true
+		}
+	}
+	return "", false
+}
+
+// JWKS 导出尚未过期的公钥，用于 /.well-known/jwks.json
+func (s *KeySet) JWKS(now time.Time) JWKS {
+	document := JWKS{Keys: []JWK{}}
+	for _, key := range s.keys {
+		if !key.NotAfter.IsZero() && !now.Before(key.NotAfter) {
+			continue
+		}
+		jwk, err := publicKeyToJWK(key.Key)
+		if err != nil {
+			continue
+		}
+		jwk.Kid = key.KID
+		jwk.Use = "sig"
+		jwk.Alg = key.Algorithm
+		if !key.NotBefore.IsZero() {
+			jwk.NotBefore = key.NotBefore.Unix()
+		}
+		if !key.NotAfter.IsZero() {
+			jwk.NotAfter = key.NotAfter.Unix()
+		}
+		document.Keys = append(document.Keys, jwk)
+	}
+	return document
+}
+
+// loadJWKSPath 读取单个 JWKS 文件，或目录下所有 .json 文件
+func loadJWKSPath(path, defaultAlgorithm string) ([]VerificationKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	var keys []VerificationKey
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileKeys, err := ParseJWKS(data, defaultAlgorithm)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	return keys, nil
+}
+
+// ParseJWKS 解析 JWKS 文档或单个 JWK
+func ParseJWKS(data []byte, defaultAlgorithm string) ([]VerificationKey, error) {
+	var document JWKS
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document.Keys == nil {
+		var single JWK
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, err
+		}
+		document.Keys = []JWK{single}
+	}
+
+	keys := make([]VerificationKey, 0, len(document.Keys))
+	for i, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := jwkToPublicKey(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+
+		key := VerificationKey{
+			KID:       jwk.Kid,
+			Algorithm: jwk.Alg,
+			Key:       publicKey,
+		}
+		if key.Algorithm == "" {
+			key.Algorithm = defaultAlgorithm
+		}
+		if key.KID == "" {
+			if key.KID, err = keyThumbprint(publicKey); err != nil {
+				return nil, err
+			}
+		}
+		if jwk.NotBefore != 0 {
+			key.NotBefore = time.Unix(jwk.NotBefore, 0)
+		}
+		if jwk.NotAfter != 0 {
+			key.NotAfter = time.Unix(jwk.NotAfter, 0)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+func jwkToPublicKey(jwk JWK) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "EC":
+		curve, ok := jwkCurves[jwk.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeJWKInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", jwk.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "RSA":
+		n, err := decodeJWKInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwk.X, "="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64url value: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key length %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+func publicKeyToJWK(publicKey crypto.PublicKey) (JWK, error) {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Crv: key.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	}
+
+	return JWK{}, fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// keyThumbprint 按 RFC 7638 计算公钥指纹，作为未指定 kid 时的默认值
+func keyThumbprint(publicKey crypto.PublicKey) (string, error) {
+	jwk, err := publicKeyToJWK(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	var canonical string
+	switch jwk.Kty {
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, jwk.Crv, jwk.X, jwk.Y)
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// ecdsaAlgorithmCurves ES 系列算法要求的曲线
+var ecdsaAlgorithmCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+// minRSAKeyBits RS/PS 系列算法接受的最小 RSA 密钥长度
+const minRSAKeyBits = 2048
+
+// validateKeyAlgorithm 确认配置的算法与公钥类型匹配，在启动时尽早暴露配置错误
+func validateKeyAlgorithm(algorithm string, publicKey crypto.PublicKey) error {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		curve, ok := ecdsaAlgorithmCurves[algorithm]
+		if !ok {
+			return fmt.Errorf("algorithm %s does not match ECDSA key", algorithm)
+		}
+		if key.Curve.Params().Name != curve {
+			return fmt.Errorf("algorithm %s requires curve %s, got %s", algorithm, curve, key.Curve.Params().Name)
+		}
+	case *rsa.PublicKey:
+		switch algorithm {
+		case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		default:
+			return fmt.Errorf("algorithm %s does not match RSA key", algorithm)
+		}
+		if key.N.BitLen() < minRSAKeyBits {
+			return fmt.Errorf("RSA key must be at least %d bits, got %d", minRSAKeyBits, key.N.BitLen())
+		}
+	case ed25519.PublicKey:
+		if algorithm != "EdDSA" {
+			return fmt.Errorf("algorithm %s does not match Ed25519 key", algorithm)
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	return nil
+}
+
+func keyThumbprintOrEmpty(publicKey crypto.PublicKey) string {
+	thumbprint, _ := keyThumbprint(publicKey)
+	return thumbprint
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64url value: %w", err)
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"webapi/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeTestJWKS(t *testing.T, dir, name string, keys ...JWK) {
+	t.Helper()
+
+	data, err := json.Marshal(JWKS{Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func signTestTokenWithKID(t *testing.T, key *ecdsa.PrivateKey, kid string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": "MenthaMC",
+		"sub": "mentha-ci",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	futureKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	toJWK := func(key *ecdsa.PrivateKey, kid string) JWK {
+		jwk, err := publicKeyToJWK(&key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jwk.Kid = kid
+		return jwk
+	}
+
+	retired := toJWK(oldKey, "2023")
+	retired.NotAfter = time.Now().Add(-time.Hour).Unix()
+	pending := toJWK(futureKey, "2025")
+	pending.NotBefore = time.Now().Add(24 * time.Hour).Unix()
+
+	dir := t.TempDir()
+	writeTestJWKS(t, dir, "a.json", retired, toJWK(newKey, "2024"))
+	writeTestJWKS(t, dir, "b.json", pending)
+
+	jwtConfig := config.JWTConfig{JWKSPath: dir, Issuer: "MenthaMC", Subject: "mentha-ci", Algorithm: "ES256"}
+	keys, err := LoadKeySet(jwtConfig)
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+
+	if _, err := ValidateJWT(signTestTokenWithKID(t, newKey, "2024"), keys, jwtConfig); err != nil {
+		t.Errorf("expected token signed by active key to be accepted: %v", err)
+	}
+	if _, err := ValidateJWT(signTestTokenWithKID(t, newKey, ""), keys, jwtConfig); err != nil {
+		t.Errorf("expected token without kid to be accepted by trying active keys: %v", err)
+	}
+	if _, err := ValidateJWT(signTestTokenWithKID(t, oldKey, "2023"), keys, jwtConfig); err == nil {
+		t.Error("expected token signed by retired key to be rejected")
+	}
+	if _, err := ValidateJWT(signTestTokenWithKID(t, futureKey, "2025"), keys, jwtConfig); err == nil {
+		t.Error("expected token signed by not yet valid key to be rejected")
+	}
+	if _, err := ValidateJWT(signTestTokenWithKID(t, newKey, "2023"), keys, jwtConfig); err == nil {
+		t.Error("expected token with mismatched kid to be rejected")
+	}
+
+	published := keys.JWKS(time.Now())
+	if len(published.Keys) != 2 {
+		t.Fatalf("expected retired key to be omitted from JWKS, got %d keys", len(published.Keys))
+	}
+	for _, jwk := range published.Keys {
+		if jwk.Kid == "2023" {
+			t.Error("retired key should not be published")
+		}
+	}
+}
+
+func TestKeySetAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		algorithm string
+		public    crypto.PublicKey
+		private   crypto.PrivateKey
+		method    jwt.SigningMethod
+	}{
+		{"RS256", "RS256", &rsaKey.PublicKey, rsaKey, jwt.SigningMethodRS256},
+		{"PS256", "PS256", &rsaKey.PublicKey, rsaKey, jwt.SigningMethodPS256},
+		{"EdDSA", "EdDSA", edPublic, edPrivate, jwt.SigningMethodEdDSA},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			der, err := x509.MarshalPKIXPublicKey(tc.public)
+			if err != nil {
+				t.Fatal(err)
+			}
+			jwtConfig := config.JWTConfig{
+				PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+				Issuer:    "MenthaMC",
+				Subject:   "mentha-ci",
+				Algorithm: tc.algorithm,
+			}
+
+			keys, err := LoadKeySet(jwtConfig)
+			if err != nil {
+				t.Fatalf("LoadKeySet: %v", err)
+			}
+
+			token := signTestToken(t, tc.private, tc.method, jwt.MapClaims{
+				"iss": "MenthaMC",
+				"sub": "mentha-ci",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			})
+			if _, err := ValidateJWT(token, keys, jwtConfig); err != nil {
+				t.Errorf("ValidateJWT: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadKeySetRejectsAlgorithmMismatch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	cases := []struct {
+		algorithm string
+		public    crypto.PublicKey
+	}{
+		{"ES256", &rsaKey.PublicKey},
+		{"EdDSA", &rsaKey.PublicKey},
+		{"HS256", &rsaKey.PublicKey},
+		{"RS256", &ecKey.PublicKey},
+		{"ES256", &ecKey.PublicKey},
+	}
+
+	for _, tc := range cases {
+		der, err := x509.MarshalPKIXPublicKey(tc.public)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = LoadKeySet(config.JWTConfig{
+			PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			Algorithm: tc.algorithm,
+		})
+		if err == nil {
+			t.Errorf("expected %s with %T to be rejected", tc.algorithm, tc.public)
+		}
+	}
+}
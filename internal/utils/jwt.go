@@ -1,67 +1,86 @@
 package utils
 
 import (
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"strings"
+	"time"
 	"webapi/internal/config"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func ValidateJWT(tokenString string, jwtConfig config.JWTConfig) error {
-	// 解析公钥
-	publicKey, err := parsePublicKey(jwtConfig.PublicKey)
-	if err != nil {
-		return fmt.Errorf("failed to parse public key: %w", err)
-	}
-
-	// 解析和验证 token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名方法
-		if token.Method.Alg() != jwtConfig.Algorithm {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return publicKey, nil
-	})
+func ValidateJWT(tokenString string, keys *KeySet, jwtConfig config.JWTConfig) (jwt.MapClaims, error) {
+	// 解析和验证 token，公钥由 kid 选择，签名方法必须与公钥的算法一致
+	token, err := jwt.Parse(tokenString, keys.Keyfunc(time.Now()))
 
 	if err != nil {
-		return fmt.Errorf("failed to parse token: %w", err)
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if !token.Valid {
-		return fmt.Errorf("invalid token")
+		return nil, fmt.Errorf("invalid token")
 	}
 
 	// 验证 claims
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		if iss, ok := claims["iss"].(string); ok && iss != jwtConfig.Issuer {
-			return fmt.Errorf("invalid issuer")
-		}
-		if sub, ok := claims["sub"].(string); ok && sub != jwtConfig.Subject {
-			return fmt.Errorf("invalid subject")
-		}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid claims")
+	}
+	if iss, ok := claims["iss"].(string); ok && iss != jwtConfig.Issuer {
+		return nil, fmt.Errorf("invalid issuer")
+	}
+	if sub, ok := claims["sub"].(string); ok && sub != jwtConfig.Subject {
+		return nil, fmt.Errorf("invalid subject")
 	}
 
-	return nil
+	return claims, nil
 }
 
-func parsePublicKey(publicKeyPEM string) (*ecdsa.PublicKey, error) {
+// AudienceMatches 判断 token 的 aud 是否允许访问 path
+// 支持 "*" 通配所有接口，以及以 "*" 结尾的前缀匹配，如 "/v2/commit/*"
+func AudienceMatches(audiences []string, path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, aud := range audiences {
+		switch {
+		case aud == "*":
+			return true
+		case strings.HasSuffix(aud, "*"):
+			if strings.HasPrefix(path, strings.TrimSuffix(aud, "*")) {
+				return true
+			}
+		case strings.TrimSuffix(aud, "/") == path:
+			return true
+		}
+	}
+	return false
+}
+
+// parsePublicKey 解析 PEM 格式的 ECDSA、RSA 或 Ed25519 公钥
+func parsePublicKey(publicKeyPEM string) (crypto.PublicKey, error) {
 	block, _ := pem.Decode([]byte(publicKeyPEM))
 	if block == nil {
 		return nil, fmt.Errorf("failed to decode PEM block")
 	}
 
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse public key: %w", err)
 	}
 
-	ecdsaKey, ok := pub.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("not an ECDSA public key")
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
 	}
 
-	return ecdsaKey, nil
-}
\ No newline at end of file
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
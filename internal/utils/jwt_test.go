@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+	"webapi/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestJWTConfig(t *testing.T) (config.JWTConfig, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return config.JWTConfig{
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		Issuer:    "MenthaMC",
+		Subject:   "mentha-ci",
+		Algorithm: "ES256",
+	}, privateKey
+}
+
+func signTestToken(t *testing.T, key interface{}, method jwt.SigningMethod, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestValidateJWT(t *testing.T) {
+	jwtConfig, privateKey := newTestJWTConfig(t)
+
+	token := signTestToken(t, privateKey, jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": "MenthaMC",
+		"sub": "mentha-ci",
+		"aud": "/v2/commit/build",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	keys, err := LoadKeySet(jwtConfig)
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+
+	claims, err := ValidateJWT(token, keys, jwtConfig)
+	if err != nil {
+		t.Fatalf("ValidateJWT: %v", err)
+	}
+	if audiences, _ := claims.GetAudience(); len(audiences) != 1 || audiences[0] != "/v2/commit/build" {
+		t.Errorf("unexpected audience %v", audiences)
+	}
+
+	wrongIssuer := signTestToken(t, privateKey, jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": "someone-else",
+		"sub": "mentha-ci",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := ValidateJWT(wrongIssuer, keys, jwtConfig); err == nil {
+		t.Error("expected token with wrong issuer to be rejected")
+	}
+}
+
+func TestAudienceMatches(t *testing.T) {
+	cases := []struct {
+		audiences []string
+		path      string
+		want      bool
+	}{
+		{[]string{"*"}, "/v2/commit/build", true},
+		{[]string{"/v2/commit/build"}, "/v2/commit/build", true},
+		{[]string{"/v2/commit/build"}, "/v2/commit/build/", true},
+		{[]string{"/v2/commit/build/download_source"}, "/v2/commit/build", false},
+		{[]string{"/v2/commit/build"}, "/v2/commit/build/download_source", false},
+		{[]string{"/v2/commit/*"}, "/v2/commit/build/download_source", true},
+		{[]string{"/v2/commit/*"}, "/v2/delete/build/download_source", false},
+		{[]string{"/v2/delete/build/download_source", "/v2/commit/build"}, "/v2/commit/build", true},
+		{nil, "/v2/commit/build", false},
+	}
+
+	for _, tc := range cases {
+		if got := AudienceMatches(tc.audiences, tc.path); got != tc.want {
+			t.Errorf("AudienceMatches(%v, %q) = %v, want %v", tc.audiences, tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"webapi/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ClientCertRule 将客户端证书映射为可操作的项目与权限
+// Subject 与证书完整 DN（如 "CN=runner-1,O=MenthaMC"）或 CN 比较，SAN 与 DNS、邮箱、URI 或 IP 比较
+type ClientCertRule struct {
+	Subject  string   `json:"subject,omitempty"`
+	SAN      string   `json:"san,omitempty"`
+	Projects []string `json:"projects"`
+	Scopes   []string `json:"scopes"`
+}
+
+// ClientCertMapper 按顺序匹配客户端证书规则
+type ClientCertMapper struct {
+	rules []ClientCertRule
+}
+
+// LoadClientCertMapper 读取 TLS_CLIENT_MAP_FILE，未启用客户端证书时返回 nil
+func LoadClientCertMapper(tlsConfig config.TLSConfig) (*ClientCertMapper, error) {
+	if tlsConfig.ClientCAFile == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(tlsConfig.ClientMapFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", tlsConfig.ClientMapFile, err)
+	}
+
+	return ParseClientCertRules(data)
+}
+
+// ParseClientCertRules 解析 JSON 数组形式的证书映射规则
+func ParseClientCertRules(data []byte) (*ClientCertMapper, error) {
+	var rules []ClientCertRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid client certificate map: %w", err)
+	}
+
+	for i, rule := range rules {
+		if rule.Subject == "" && rule.SAN == "" {
+			return nil, fmt.Errorf("client certificate rule %d has neither subject nor san", i)
+		}
+		if len(rule.Projects) == 0 || len(rule.Scopes) == 0 {
+			return nil, fmt.Errorf("client certificate rule %d must list projects and scopes", i)
+		}
+	}
+
+	return &ClientCertMapper{rules: rules}, nil
+}
+
+// Claims 返回第一条匹配规则对应的 claims，没有匹配时返回 false
+func (m *ClientCertMapper) Claims(cert *x509.Certificate) (jwt.MapClaims, bool) {
+	for _, rule := range m.rules {
+		if rule.Subject != "" && rule.Subject != cert.Subject.String() && rule.Subject != cert.Subject.CommonName {
+			continue
+		}
+		if rule.SAN != "" && !certificateHasSAN(cert, rule.SAN) {
+			continue
+		}
+
+		return jwt.MapClaims{
+			"sub":      "cert:" + cert.Subject.String(),
+			"projects": rule.Projects,
+			"scopes":   rule.Scopes,
+		}, true
+	}
+
+	return nil, false
+}
+
+func certificateHasSAN(cert *x509.Certificate, san string) bool {
+	for _, name := range cert.DNSNames {
+		if name == san {
+			return true
+		}
+	}
+	for _, email := range cert.EmailAddresses {
+		if email == san {
+			return true
+		}
+	}
+	for _, uri := range cert.URIs {
+		if uri.String() == san {
+			return true
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		if ip.String() == san {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerTLSConfig 创建服务端 TLS 配置，设置了 TLS_CLIENT_CA_FILE 时请求并验证客户端证书
+// 公开接口不要求证书，是否必须提供由认证中间件决定
+func ServerTLSConfig(tlsConfig config.TLSConfig) (*tls.Config, error) {
+	serverConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if tlsConfig.ClientCAFile != "" {
+		data, err := os.ReadFile(tlsConfig.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", tlsConfig.ClientCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", tlsConfig.ClientCAFile)
+		}
+		serverConfig.ClientCAs = pool
+		serverConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return serverConfig, nil
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+)
+
+func TestClientCertMapper(t *testing.T) {
+	mapper, err := ParseClientCertRules([]byte(`[
+		{"subject": "runner-1", "projects": ["mint"], "scopes": ["commit_build"]},
+		{"san": "leaves.runners.menthamc.org", "projects": ["leaves"], "scopes": ["commit_build", "manage_download_sources"]}
+	]`))
+	if err != nil {
+		t.Fatalf("ParseClientCertRules: %v", err)
+	}
+
+	runner := &x509.Certificate{Subject: pkix.Name{CommonName: "runner-1", Organization: []string{"MenthaMC"}}}
+	claims, ok := mapper.Claims(runner)
+	if !ok {
+		t.Fatal("expected runner-1 to match by CN")
+	}
+	if err := CheckScope(claims, ScopeCommitBuild, "mint", false); err != nil {
+		t.Errorf("expected runner-1 to commit to mint: %v", err)
+	}
+	if err := CheckScope(claims, ScopeCommitBuild, "leaves", false); err == nil {
+		t.Error("expected runner-1 not to commit to leaves")
+	}
+
+	leaves := &x509.Certificate{Subject: pkix.Name{CommonName: "runner-2"}, DNSNames: []string{"leaves.runners.menthamc.org"}}
+	claims, ok = mapper.Claims(leaves)
+	if !ok {
+		t.Fatal("expected runner-2 to match by SAN")
+	}
+	if err := CheckScope(claims, ScopeManageDownloadSources, "leaves", false); err != nil {
+		t.Errorf("expected runner-2 to manage leaves download sources: %v", err)
+	}
+
+	if _, ok := mapper.Claims(&x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}}); ok {
+		t.Error("expected an unmapped certificate not to match")
+	}
+
+	if _, err := ParseClientCertRules([]byte(`[{"projects": ["mint"], "scopes": ["commit_build"]}]`)); err == nil {
+		t.Error("expected a rule without subject or san to be rejected")
+	}
+}
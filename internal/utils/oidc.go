@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"webapi/internal/config"
+	"webapi/internal/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oidcRefetchInterval 遇到未知 kid 时重新拉取 JWKS 的最小间隔
+const oidcRefetchInterval = time.Minute
+
+// OIDCVerifier 验证 GitHub Actions 签发的 OIDC token
+// JWKS 可以是 https 地址，也可以是本地文件（用于测试或离线环境）
+type OIDCVerifier struct {
+	issuer   string
+	audience string
+	source   string
+	client   *http.Client
+
+	mu        sync.Mutex
+	keys      *KeySet
+	fetchedAt time.Time
+}
+
+// NewOIDCVerifier 按配置创建验证器，未启用时返回 nil
+func NewOIDCVerifier(oidcConfig config.GitHubOIDCConfig) (*OIDCVerifier, error) {
+	if !oidcConfig.Enabled {
+		return nil, nil
+	}
+	if oidcConfig.Audience == "" {
+		return nil, fmt.Errorf("GITHUB_OIDC_AUDIENCE must be set when GitHub OIDC is enabled")
+	}
+
+	v := &OIDCVerifier{
+		issuer:   oidcConfig.Issuer,
+		audience: oidcConfig.Audience,
+		source:   oidcConfig.JWKS,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if err := v.refresh(); err != nil {
+		// 远程 JWKS 暂时不可用时不阻止启动，首次验证时会重试
+		if v.isRemote() {
+			logger.Warnf("Failed to fetch GitHub OIDC JWKS from %s: %v", v.source, err)
+		} else {
+			return nil, fmt.Errorf("failed to load GitHub OIDC JWKS: %w", err)
+		}
+	}
+
+	return v, nil
+}
+
+// Issuer 返回受信任的 OIDC issuer
+func (v *OIDCVerifier) Issuer() string {
+	return v.issuer
+}
+
+// Verify 校验签名、有效期、issuer 与 audience，返回 token 的 claims
+func (v *OIDCVerifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	keys, err := v.keysFor(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(tokenString, keys.Keyfunc(time.Now()),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OIDC token: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid OIDC token")
+	}
+	if repository, _ := claims["repository"].(string); repository == "" {
+		return nil, fmt.Errorf("OIDC token has no repository claim")
+	}
+
+	return claims, nil
+}
+
+// keysFor 返回当前 JWKS，token 使用未知 kid 时按间隔限制重新拉取
+func (v *OIDCVerifier) keysFor(tokenString string) (*KeySet, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	kid := tokenKeyID(tokenString)
+	known := v.keys != nil && (kid == "" || v.keys.hasKeyID(kid))
+
+	if !known && time.Since(v.fetchedAt) >= oidcRefetchInterval {
+		if err := v.refreshLocked(); err != nil {
+			logger.Warnf("Failed to refresh GitHub OIDC JWKS: %v", err)
+		}
+	}
+
+	if v.keys == nil {
+		return nil, fmt.Errorf("GitHub OIDC JWKS is not available")
+	}
+	return v.keys, nil
+}
+
+func (v *OIDCVerifier) refresh() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.refreshLocked()
+}
+
+func (v *OIDCVerifier) refreshLocked() error {
+	v.fetchedAt = time.Now()
+
+	data, err := v.read()
+	if err != nil {
+		return err
+	}
+
+	keys, err := ParseJWKS(data, "RS256")
+	if err != nil {
+		return err
+	}
+	set, err := NewKeySet(keys)
+	if err != nil {
+		return err
+	}
+
+	v.keys = set
+	return nil
+}
+
+func (v *OIDCVerifier) read() ([]byte, error) {
+	if !v.isRemote() {
+		return os.ReadFile(strings.TrimPrefix(v.source, "file://"))
+	}
+
+	resp, err := v.client.Get(v.source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func (v *OIDCVerifier) isRemote() bool {
+	return strings.HasPrefix(v.source, "https://") || strings.HasPrefix(v.source, "http://")
+}
+
+// TokenIssuer 读取未经验证的 iss claim，仅用于选择验证方式
+func TokenIssuer(tokenString string) string {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return ""
+	}
+	issuer, _ := token.Claims.GetIssuer()
+	return issuer
+}
+
+func tokenKeyID(tokenString string) string {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return ""
+	}
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
+
+// CheckRepositoryScope 校验 OIDC token 的 repository claim 是否与项目仓库一致，且 action 在授予的操作内
+func CheckRepositoryScope(claims jwt.MapClaims, action, projectRepo string, scopes []string) error {
+	if !containsAny(scopes, action) {
+		return fmt.Errorf("GitHub OIDC tokens are not allowed to %s", action)
+	}
+
+	repository, _ := claims["repository"].(string)
+	if repository == "" || normalizeRepository(repository) != normalizeRepository(projectRepo) {
+		return fmt.Errorf("repository %s does not match the project repository", repository)
+	}
+
+	return nil
+}
+
+// normalizeRepository 将 https://github.com/owner/repo.git 等形式统一为 owner/repo
+func normalizeRepository(repo string) string {
+	repo = strings.ToLower(strings.TrimSpace(repo))
+	for _, prefix := range []string{"https://", "http://", "github.com/"} {
+		repo = strings.TrimPrefix(repo, prefix)
+	}
+	repo = strings.TrimSuffix(repo, "/")
+	return strings.TrimSuffix(repo, ".git")
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"path/filepath"
+	"testing"
+	"time"
+	"webapi/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestOIDCVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := publicKeyToJWK(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk.Kid = "github-test"
+
+	// 用本地 JWKS 文件代替 GitHub 的 JWKS 地址
+	dir := t.TempDir()
+	writeTestJWKS(t, dir, "jwks.json", jwk)
+
+	const issuer = "https://token.actions.githubusercontent.com"
+	verifier, err := NewOIDCVerifier(config.GitHubOIDCConfig{
+		Enabled:  true,
+		Issuer:   issuer,
+		JWKS:     filepath.Join(dir, "jwks.json"),
+		Audience: "https://api.menthamc.org",
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCVerifier: %v", err)
+	}
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "github-test"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	claims := func(aud string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":        issuer,
+			"aud":        aud,
+			"sub":        "repo:MenthaMC/Mint:ref:refs/heads/main",
+			"repository": "MenthaMC/Mint",
+			"exp":        time.Now().Add(5 * time.Minute).Unix(),
+		}
+	}
+
+	token := sign(claims("https://api.menthamc.org"))
+	if TokenIssuer(token) != issuer {
+		t.Fatalf("expected issuer %s, got %s", issuer, TokenIssuer(token))
+	}
+
+	verified, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("expected token to be accepted: %v", err)
+	}
+	if _, err := verifier.Verify(sign(claims("https://other.example"))); err == nil {
+		t.Error("expected token with another audience to be rejected")
+	}
+
+	scopes := []string{ScopeCommitBuild}
+	if err := CheckRepositoryScope(verified, ScopeCommitBuild, "https://github.com/menthamc/mint.git", scopes); err != nil {
+		t.Errorf("expected matching repository to be allowed: %v", err)
+	}
+	if err := CheckRepositoryScope(verified, ScopeCommitBuild, "https://github.com/MenthaMC/Leaves", scopes); err == nil {
+		t.Error("expected another repository to be rejected")
+	}
+	if err := CheckRepositoryScope(verified, ScopeDelete, "MenthaMC/Mint", scopes); err == nil {
+		t.Error("expected action outside the granted scopes to be rejected")
+	}
+}
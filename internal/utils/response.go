@@ -67,6 +67,7 @@ func BuildToBuildResponse(build models.Build, changes []models.ChangeResponse) m
 		downloads[source] = models.DownloadInfo{
 			Name:   build.JarName,
 			SHA256: build.SHA256,
+			SHA512: build.SHA512,
 		}
 	}
 
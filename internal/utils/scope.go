@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ClaimsContextKey 认证中间件在 gin.Context 中保存已验证 claims 的键
+const ClaimsContextKey = "claims"
+
+// AuthMethodContextKey 认证中间件在 gin.Context 中保存认证方式的键
+const AuthMethodContextKey = "auth_method"
+
+// 认证方式
+const (
+	AuthMethodJWT        = "jwt"
+	AuthMethodGitHubOIDC = "github_oidc"
+	AuthMethodAPIKey     = "api_key"
+	AuthMethodClientCert = "client_cert"
+)
+
+// token 中 scopes claim 可用的操作
+const (
+	ScopeCommitBuild           = "commit_build"
+	ScopeManageDownloadSources = "manage_download_sources"
+	ScopeDelete                = "delete"
+	ScopeManageVersions        = "manage_versions"
+	ScopeAdmin                 = "admin"
+)
+
+// CheckScope 校验 claims 中的 projects 与 scopes 是否允许对项目执行指定操作
+// projects 支持 "*" 表示所有项目，scopes 中的 admin 包含所有操作
+// allowUnscoped 为 true 时，同时缺少 projects 与 scopes 的旧 token 视为拥有全部权限
+func CheckScope(claims jwt.MapClaims, action, projectID string, allowUnscoped bool) error {
+	if allowUnscoped && IsUnscopedToken(claims) {
+		return nil
+	}
+
+	projects, _ := claimStrings(claims, "projects")
+	scopes, _ := claimStrings(claims, "scopes")
+
+	if !containsAny(scopes, action, ScopeAdmin) {
+		return fmt.Errorf("token is missing scope %s", action)
+	}
+	if !containsAny(projects, projectID, "*") {
+		return fmt.Errorf("token is not allowed to access project %s", projectID)
+	}
+
+	return nil
+}
+
+// IsUnscopedToken 判断 claims 是否同时缺少 projects 与 scopes
+func IsUnscopedToken(claims jwt.MapClaims) bool {
+	_, hasProjects := claims["projects"]
+	_, hasScopes := claims["scopes"]
+	return !hasProjects && !hasScopes
+}
+
+// claimStrings 读取字符串或字符串数组形式的 claim
+func claimStrings(claims jwt.MapClaims, key string) ([]string, bool) {
+	raw, ok := claims[key]
+	if !ok {
+		return nil, false
+	}
+
+	switch value := raw.(type) {
+	case string:
+		return []string{value}, true
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values, true
+	case []string:
+		return value, true
+	}
+
+	return nil, true
+}
+
+func containsAny(values []string, candidates ...string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
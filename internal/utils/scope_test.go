@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestCheckScope(t *testing.T) {
+	ciToken := jwt.MapClaims{
+		"projects": []interface{}{"mint"},
+		"scopes":   []interface{}{ScopeCommitBuild, ScopeManageDownloadSources},
+	}
+
+	if err := CheckScope(ciToken, ScopeCommitBuild, "mint", false); err != nil {
+		t.Errorf("expected commit to mint to be allowed: %v", err)
+	}
+	if err := CheckScope(ciToken, ScopeCommitBuild, "leaves", false); err == nil {
+		t.Error("expected commit to another project to be rejected")
+	}
+	if err := CheckScope(ciToken, ScopeDelete, "mint", false); err == nil {
+		t.Error("expected missing delete scope to be rejected")
+	}
+
+	adminToken := jwt.MapClaims{"projects": "*", "scopes": ScopeAdmin}
+	if err := CheckScope(adminToken, ScopeDelete, "leaves", false); err != nil {
+		t.Errorf("expected admin token to be allowed: %v", err)
+	}
+
+	legacyToken := jwt.MapClaims{"aud": "*"}
+	if err := CheckScope(legacyToken, ScopeCommitBuild, "mint", false); err == nil {
+		t.Error("expected unscoped token to be rejected")
+	}
+	if err := CheckScope(legacyToken, ScopeCommitBuild, "mint", true); err != nil {
+		t.Errorf("expected unscoped token to be allowed in legacy mode: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// SignDownload 为指定构建的某个下载源生成带过期时间的 HMAC 签名
+func SignDownload(secret, projectID, version string, buildID int, downloadSource string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s\n%d", projectID, version, buildID, downloadSource, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyDownloadSignature 校验下载签名是否匹配且未过期
+func VerifyDownloadSignature(secret, projectID, version string, buildID int, downloadSource string, expires int64, signature string) error {
+	if secret == "" {
+		return fmt.Errorf("signed downloads are not configured")
+	}
+	if time.Now().Unix() > expires {
+		return fmt.Errorf("signature expired")
+	}
+
+	expected := SignDownload(secret, projectID, version, buildID, downloadSource, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("invalid signature")
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyDownloadSignature(t *testing.T) {
+	secret := "test-secret"
+	expires := time.Now().Add(time.Hour).Unix()
+	signature := SignDownload(secret, "mint", "1.21.4", 12, "application", expires)
+
+	if err := VerifyDownloadSignature(secret, "mint", "1.21.4", 12, "application", expires, signature); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	if err := VerifyDownloadSignature(secret, "mint", "1.21.4", 13, "application", expires, signature); err == nil {
+		t.Error("expected signature for another build to be rejected")
+	}
+
+	if err := VerifyDownloadSignature(secret, "mint", "1.21.4", 12, "mirror", expires, signature); err == nil {
+		t.Error("expected signature for another download source to be rejected")
+	}
+
+	expired := time.Now().Add(-time.Minute).Unix()
+	expiredSignature := SignDownload(secret, "mint", "1.21.4", 12, "application", expired)
+	if err := VerifyDownloadSignature(secret, "mint", "1.21.4", 12, "application", expired, expiredSignature); err == nil {
+		t.Error("expected expired signature to be rejected")
+	}
+
+	if err := VerifyDownloadSignature("", "mint", "1.21.4", 12, "application", expires, signature); err == nil {
+		t.Error("expected verification without a secret to fail")
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"time"
+	"webapi/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenOptions 签发 token 时可指定的 claims
+type TokenOptions struct {
+	Audience []string
+	Projects []string
+	Scopes   []string
+	TTL      time.Duration
+	KeyID    string
+}
+
+// IssueToken 使用配置的私钥、issuer、subject 与算法签发 token，返回 token 与其 jti
+func IssueToken(jwtConfig config.JWTConfig, opts TokenOptions) (string, string, error) {
+	signer, err := ParsePrivateKey(jwtConfig.PrivateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse API_PRIVATE_KEY: %w", err)
+	}
+	if err := validateKeyAlgorithm(jwtConfig.Algorithm, signer.Public()); err != nil {
+		return "", "", err
+	}
+
+	method := jwt.GetSigningMethod(jwtConfig.Algorithm)
+	if method == nil {
+		return "", "", fmt.Errorf("unsupported algorithm %s", jwtConfig.Algorithm)
+	}
+
+	jti, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":      jwtConfig.Issuer,
+		"sub":      jwtConfig.Subject,
+		"aud":      opts.Audience,
+		"projects": opts.Projects,
+		"scopes":   opts.Scopes,
+		"jti":      jti,
+		"iat":      now.Unix(),
+	}
+	if opts.TTL > 0 {
+		claims["exp"] = now.Add(opts.TTL).Unix()
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+
+	kid := opts.KeyID
+	if kid == "" {
+		if kid, err = keyThumbprint(signer.Public()); err != nil {
+			return "", "", err
+		}
+	}
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(signer)
+	if err != nil {
+		return "", "", err
+	}
+
+	return signed, jti, nil
+}
+
+// GenerateKeyPair 按算法生成 PEM 编码的私钥（PKCS#8）与公钥（PKIX）
+func GenerateKeyPair(algorithm string) (string, string, error) {
+	var signer crypto.Signer
+	var err error
+
+	switch algorithm {
+	case "ES256":
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ES384":
+		signer, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ES512":
+		signer, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		signer, err = rsa.GenerateKey(rand.Reader, 3072)
+	case "EdDSA":
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", "", fmt.Errorf("unsupported algorithm %s", algorithm)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return "", "", err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return "", "", err
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	return string(privatePEM), string(publicPEM), nil
+}
+
+// ParsePrivateKey 解析 PKCS#8、SEC 1 或 PKCS#1 格式的 PEM 私钥
+func ParsePrivateKey(privateKeyPEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
+
+// PublicKeyJWK 导出公钥的 JWK 表示，kid 为 RFC 7638 指纹
+func PublicKeyJWK(publicKey crypto.PublicKey, algorithm string) (JWK, error) {
+	jwk, err := publicKeyToJWK(publicKey)
+	if err != nil {
+		return JWK{}, err
+	}
+	if jwk.Kid, err = keyThumbprint(publicKey); err != nil {
+		return JWK{}, err
+	}
+	jwk.Use = "sig"
+	jwk.Alg = algorithm
+	return jwk, nil
+}
+
+func newTokenID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
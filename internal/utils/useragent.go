@@ -0,0 +1,39 @@
+package utils
+
+import "strings"
+
+// userAgentFamilies 按顺序匹配，越具体的规则越靠前
+var userAgentFamilies = []struct {
+	token  string
+	family string
+}{
+	{"curl/", "curl"},
+	{"wget/", "wget"},
+	{"go-http-client/", "go"},
+	{"python-requests/", "python"},
+	{"python-urllib/", "python"},
+	{"okhttp/", "okhttp"},
+	{"java/", "java"},
+	{"java-http-client/", "java"},
+	{"edg/", "edge"},
+	{"opr/", "opera"},
+	{"firefox/", "firefox"},
+	{"chrome/", "chrome"},
+	{"safari/", "safari"},
+}
+
+// UserAgentFamily 将 User-Agent 归类为粗粒度的客户端家族，避免保存完整的 UA 字符串
+func UserAgentFamily(userAgent string) string {
+	if userAgent == "" {
+		return "unknown"
+	}
+
+	ua := strings.ToLower(userAgent)
+	for _, f := range userAgentFamilies {
+		if strings.Contains(ua, f.token) {
+			return f.family
+		}
+	}
+
+	return "other"
+}
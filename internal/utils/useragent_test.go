@@ -0,0 +1,24 @@
+package utils
+
+import "testing"
+
+func TestUserAgentFamily(t *testing.T) {
+	cases := map[string]string{
+		"":                         "unknown",
+		"curl/8.4.0":               "curl",
+		"Wget/1.21.4":              "wget",
+		"Java/17.0.9":              "java",
+		"Go-http-client/1.1":       "go",
+		"python-requests/2.31.0":   "python",
+		"SomeLauncher/1.0 (Linux)": "other",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36":           "chrome",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0": "edge",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0":                                                    "firefox",
+	}
+
+	for ua, want := range cases {
+		if got := UserAgentFamily(ua); got != want {
+			t.Errorf("UserAgentFamily(%q) = %q, want %q", ua, got, want)
+		}
+	}
+}
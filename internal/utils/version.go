@@ -0,0 +1,367 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 项目可选的版本号规则
+const (
+	VersionSchemeMinecraft = "minecraft"
+	VersionSchemeSemver    = "semver"
+	VersionSchemeCalver    = "calver"
+)
+
+// VersionComparator 比较同一项目的两个版本号，a 早于 b 时返回负数
+type VersionComparator func(a, b string) int
+
+// versionComparators 按规则名登记比较器的构造函数
+// known 为项目已有的版本名，按创建顺序排列，用于无法仅凭名称排序的版本（如 Minecraft 快照）
+var versionComparators = map[string]func(known []string) VersionComparator{
+	VersionSchemeMinecraft: newMinecraftComparator,
+	VersionSchemeSemver:    newSemverComparator,
+	VersionSchemeCalver:    newCalverComparator,
+}
+
+// IsVersionScheme 判断是否为支持的版本号规则
+func IsVersionScheme(scheme string) bool {
+	_, ok := versionComparators[scheme]
+	return ok
+}
+
+// NewVersionComparator 创建指定规则的比较器
+func NewVersionComparator(scheme string, known []string) (VersionComparator, error) {
+	factory, ok := versionComparators[scheme]
+	if !ok {
+		return nil, fmt.Errorf("unknown version scheme %q", scheme)
+	}
+	return factory(known), nil
+}
+
+// SortVersionsDescending 将按创建顺序排列的版本名排为从新到旧，swap 用于同步调整调用方的其他切片
+func SortVersionsDescending(scheme string, names []string, swap func(i, j int)) error {
+	compare, err := NewVersionComparator(scheme, names)
+	if err != nil {
+		return err
+	}
+
+	sort.Sort(versionSorter{names: names, compare: compare, swap: swap})
+	return nil
+}
+
+type versionSorter struct {
+	names   []string
+	compare VersionComparator
+	swap    func(i, j int)
+}
+
+func (s versionSorter) Len() int           { return len(s.names) }
+func (s versionSorter) Less(i, j int) bool { return s.compare(s.names[i], s.names[j]) > 0 }
+func (s versionSorter) Swap(i, j int) {
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+	if s.swap != nil {
+		s.swap(i, j)
+	}
+}
+
+// knownIndex 返回版本的创建顺序，未登记的版本视为最新创建
+func knownIndex(known []string) func(name string) int {
+	index := make(map[string]int, len(known))
+	for i, name := range known {
+		index[name] = i
+	}
+	return func(name string) int {
+		if i, ok := index[name]; ok {
+			return i
+		}
+		return len(known)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareNumbers 逐段比较数字，缺少的段按 0 处理
+func compareNumbers(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareInts(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func parseNumbers(value string) ([]int, bool) {
+	parts := strings.Split(value, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || strings.HasPrefix(part, "+") {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
+
+// Minecraft 版本的阶段，快照及无法识别的版本排在其所属正式版的预发布之前
+const (
+	minecraftStageSnapshot = iota
+	minecraftStagePre
+	minecraftStageRC
+	minecraftStageRelease
+)
+
+var (
+	minecraftReleasePattern  = regexp.MustCompile(`^\d+(\.\d+)+$`)
+	minecraftPrePattern      = regexp.MustCompile(`^(\d+(?:\.\d+)+)(?:-(pre|rc)| (Pre-Release|Release Candidate) )(\d+)$`)
+	minecraftSnapshotPattern = regexp.MustCompile(`^(\d{2})w(\d{2})([a-z])$`)
+)
+
+type minecraftVersion struct {
+	// release 为正式版及其预发布的版本号；快照取之后最先创建的正式版或预发布，仍在开发中时为 nil
+	release  []int
+	stage    int
+	stageNum int
+	// snapshot 为快照的年、周与字母，其他无法识别的版本为 nil
+	snapshot []int
+	index    int
+}
+
+// newMinecraftComparator 正式版按版本号排序，同一版本号下 pre < rc < 正式版
+// 快照无法从名称得知对应的正式版，归入其后最先创建的正式版或预发布之前，同一开发周期内按年、周、字母排序
+func newMinecraftComparator(known []string) VersionComparator {
+	indexOf := knownIndex(known)
+
+	parsed := make([]minecraftVersion, len(known))
+	for i, name := range known {
+		parsed[i] = parseMinecraftVersion(name, i)
+	}
+	// 从后往前为快照确定所属的正式版
+	var next []int
+	for i := len(parsed) - 1; i >= 0; i-- {
+		if parsed[i].stage == minecraftStageSnapshot {
+			parsed[i].release = next
+		} else {
+			next = parsed[i].release
+		}
+	}
+	cache := make(map[string]minecraftVersion, len(known))
+	for i, name := range known {
+		cache[name] = parsed[i]
+	}
+
+	lookup := func(name string) minecraftVersion {
+		if version, ok := cache[name]; ok {
+			return version
+		}
+		return parseMinecraftVersion(name, indexOf(name))
+	}
+
+	return func(a, b string) int {
+		x, y := lookup(a), lookup(b)
+
+		// 仍在开发中的快照比所有正式版都新
+		switch {
+		case x.release == nil && y.release != nil:
+			return 1
+		case x.release != nil && y.release == nil:
+			return -1
+		}
+		if c := compareNumbers(x.release, y.release); c != 0 {
+			return c
+		}
+		if c := compareInts(x.stage, y.stage); c != 0 {
+			return c
+		}
+		if c := compareInts(x.stageNum, y.stageNum); c != 0 {
+			return c
+		}
+		if x.snapshot != nil && y.snapshot != nil {
+			if c := compareNumbers(x.snapshot, y.snapshot); c != 0 {
+				return c
+			}
+		}
+		return compareInts(x.index, y.index)
+	}
+}
+
+func parseMinecraftVersion(name string, index int) minecraftVersion {
+	version := minecraftVersion{stage: minecraftStageSnapshot, index: index}
+
+	if minecraftReleasePattern.MatchString(name) {
+		version.release, _ = parseNumbers(name)
+		version.stage = minecraftStageRelease
+		return version
+	}
+
+	if match := minecraftPrePattern.FindStringSubmatch(name); match != nil {
+		version.release, _ = parseNumbers(match[1])
+		version.stage = minecraftStagePre
+		if match[2] == "rc" || match[3] == "Release Candidate" {
+			version.stage = minecraftStageRC
+		}
+		version.stageNum, _ = strconv.Atoi(match[4])
+		return version
+	}
+
+	if match := minecraftSnapshotPattern.FindStringSubmatch(name); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		week, _ := strconv.Atoi(match[2])
+		version.snapshot = []int{year, week, int(match[3][0])}
+	}
+
+	return version
+}
+
+// dottedVersion 为 semver 与 calver 共用的解析结果
+type dottedVersion struct {
+	valid      bool
+	numbers    []int
+	prerelease []string
+	index      int
+}
+
+// newSemverComparator 按 Semantic Versioning 2.0 的优先级排序，忽略构建元数据
+// 不符合规范的版本排在所有合法版本之前，彼此按创建顺序排序
+func newSemverComparator(known []string) VersionComparator {
+	return newDottedComparator(known, parseSemver)
+}
+
+// newCalverComparator 按日期各段的数值排序（如 2024.04.1），段数不限，"-" 之后的部分视为预发布
+func newCalverComparator(known []string) VersionComparator {
+	return newDottedComparator(known, parseCalver)
+}
+
+func newDottedComparator(known []string, parse func(name string) dottedVersion) VersionComparator {
+	indexOf := knownIndex(known)
+
+	return func(a, b string) int {
+		x, y := parse(a), parse(b)
+		x.index, y.index = indexOf(a), indexOf(b)
+
+		if x.valid != y.valid {
+			if x.valid {
+				return 1
+			}
+			return -1
+		}
+		if x.valid {
+			if c := compareNumbers(x.numbers, y.numbers); c != 0 {
+				return c
+			}
+			if c := comparePrerelease(x.prerelease, y.prerelease); c != 0 {
+				return c
+			}
+		}
+		return compareInts(x.index, y.index)
+	}
+}
+
+func parseSemver(name string) dottedVersion {
+	core := strings.TrimPrefix(name, "v")
+	if i := strings.IndexByte(core, '+'); i >= 0 {
+		core = core[:i]
+	}
+
+	version := splitPrerelease(core)
+	if !version.valid || len(version.numbers) != 3 {
+		return dottedVersion{}
+	}
+	return version
+}
+
+func parseCalver(name string) dottedVersion {
+	return splitPrerelease(strings.TrimPrefix(name, "v"))
+}
+
+func splitPrerelease(value string) dottedVersion {
+	core, prerelease, hasPrerelease := strings.Cut(value, "-")
+
+	numbers, ok := parseNumbers(core)
+	if !ok || (hasPrerelease && prerelease == "") {
+		return dottedVersion{}
+	}
+
+	version := dottedVersion{valid: true, numbers: numbers}
+	if hasPrerelease {
+		version.prerelease = strings.Split(prerelease, ".")
+	}
+	return version
+}
+
+// comparePrerelease 没有预发布标识的版本更新；数字标识按数值比较且小于字母标识
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, xErr := strconv.Atoi(a[i])
+		y, yErr := strconv.Atoi(b[i])
+		switch {
+		case xErr == nil && yErr == nil:
+			if c := compareInts(x, y); c != 0 {
+				return c
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+// DefaultVersionGroupRule 取版本号的前两段作为版本组，如 1.21.4 → 1.21
+const DefaultVersionGroupRule = `^(\d+\.\d+)`
+
+// DeriveVersionGroup 按项目的规则从版本名得出版本组名
+// rule 为正则表达式，有捕获组时取第一个捕获组，否则取整个匹配
+func DeriveVersionGroup(rule, version string) (string, error) {
+	pattern, err := regexp.Compile(rule)
+	if err != nil {
+		return "", fmt.Errorf("invalid version group rule: %w", err)
+	}
+
+	match := pattern.FindStringSubmatch(version)
+	if match == nil {
+		return "", fmt.Errorf("version %s does not match the version group rule %s", version, rule)
+	}
+
+	group := match[0]
+	if len(match) > 1 {
+		group = match[1]
+	}
+	if group == "" {
+		return "", fmt.Errorf("version group rule %s derives an empty group from %s", rule, version)
+	}
+
+	return group, nil
+}
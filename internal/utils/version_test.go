@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortVersionsDescending(t *testing.T) {
+	cases := []struct {
+		scheme string
+		// 按创建顺序排列
+		known    []string
+		expected []string
+	}{
+		{
+			scheme:   VersionSchemeMinecraft,
+			known:    []string{"1.9", "1.20.6", "1.21", "1.21.1", "1.21.10", "1.21.2"},
+			expected: []string{"1.21.10", "1.21.2", "1.21.1", "1.21", "1.20.6", "1.9"},
+		},
+		{
+			scheme: VersionSchemeMinecraft,
+			known: []string{
+				"1.20.4", "23w51a", "24w14a", "24w13a", "1.20.5-pre1", "1.20.5-rc1", "1.20.5",
+				"1.14 Pre-Release 2", "1.14 Pre-Release 10", "24w18a",
+			},
+			expected: []string{
+				"24w18a", "1.20.5", "1.20.5-rc1", "1.20.5-pre1", "24w14a", "24w13a", "23w51a",
+				"1.20.4", "1.14 Pre-Release 10", "1.14 Pre-Release 2",
+			},
+		},
+		{
+			scheme:   VersionSchemeSemver,
+			known:    []string{"1.0.0", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "v1.10.0", "1.2.0+build.5", "nightly"},
+			expected: []string{"v1.10.0", "1.2.0+build.5", "1.0.0", "1.0.0-rc.1", "1.0.0-beta.11", "1.0.0-beta.2", "1.0.0-alpha.1", "1.0.0-alpha", "nightly"},
+		},
+		{
+			scheme:   VersionSchemeCalver,
+			known:    []string{"2023.12", "2024.4.1", "2024.04", "2024.10.0-beta", "2024.10.0", "2024.9.15"},
+			expected: []string{"2024.10.0", "2024.10.0-beta", "2024.9.15", "2024.4.1", "2024.04", "2023.12"},
+		},
+	}
+
+	for _, tc := range cases {
+		names := append([]string(nil), tc.known...)
+		positions := make([]int, len(names))
+		for i := range positions {
+			positions[i] = i
+		}
+
+		err := SortVersionsDescending(tc.scheme, names, func(i, j int) {
+			positions[i], positions[j] = positions[j], positions[i]
+		})
+		if err != nil {
+			t.Fatalf("SortVersionsDescending(%s): %v", tc.scheme, err)
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.scheme, tc.expected, names)
+		}
+		for i, position := range positions {
+			if tc.known[position] != names[i] {
+				t.Errorf("%s: swap callback out of sync at %d", tc.scheme, i)
+			}
+		}
+	}
+
+	if err := SortVersionsDescending("alphabetical", []string{"a"}, nil); err == nil {
+		t.Error("expected an unknown scheme to be rejected")
+	}
+}
+
+func TestMinecraftComparatorUnknownVersion(t *testing.T) {
+	compare, err := NewVersionComparator(VersionSchemeMinecraft, []string{"1.21.3", "24w44a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 尚未登记的正式版早于仍在开发中的快照
+	if compare("1.21.4", "24w44a") >= 0 {
+		t.Error("expected 1.21.4 to sort before the in-development snapshot 24w44a")
+	}
+	if compare("1.21.4", "1.21.3") <= 0 {
+		t.Error("expected 1.21.4 to sort after 1.21.3")
+	}
+}
+
+func TestDeriveVersionGroup(t *testing.T) {
+	cases := []struct {
+		rule, version, expected string
+	}{
+		{DefaultVersionGroupRule, "1.21.4", "1.21"},
+		{DefaultVersionGroupRule, "1.21", "1.21"},
+		{DefaultVersionGroupRule, "1.21.5-pre1", "1.21"},
+		{`^\d+`, "2024.10.1", "2024"},
+		{`^(\d+)\.\d+\.\d+$`, "3.2.1", "3"},
+	}
+	for _, tc := range cases {
+		group, err := DeriveVersionGroup(tc.rule, tc.version)
+		if err != nil || group != tc.expected {
+			t.Errorf("DeriveVersionGroup(%q, %q) = %q, %v; expected %q", tc.rule, tc.version, group, err, tc.expected)
+		}
+	}
+
+	for _, tc := range []struct{ rule, version string }{
+		{DefaultVersionGroupRule, "24w14a"},
+		{`^(\d*)`, "snapshot"},
+		{`^(\d+`, "1.21.4"},
+	} {
+		if group, err := DeriveVersionGroup(tc.rule, tc.version); err == nil {
+			t.Errorf("expected DeriveVersionGroup(%q, %q) to fail, got %q", tc.rule, tc.version, group)
+		}
+	}
+}
@@ -3,13 +3,24 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 	"webapi/internal/app"
+	"webapi/internal/cli"
 	"webapi/internal/config"
 	"webapi/internal/database"
 	"webapi/internal/logger"
+	"webapi/internal/utils"
 )
 
 func main() {
+	// 命令行子命令（keygen、token issue、service-account）
+	if len(os.Args) > 1 {
+		if err := cli.Run(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// 加载配置
 	cfg, err := config.Load()
 	if err != nil {
@@ -19,6 +30,24 @@ func main() {
 	// 初始化日志
 	logger.Init(cfg.LogLevel)
 
+	// 加载 JWT 验证公钥
+	keys, err := utils.LoadKeySet(cfg.JWT)
+	if err != nil {
+		log.Fatalf("Failed to load JWT verification keys: %v", err)
+	}
+
+	// 启用时加载 GitHub Actions OIDC 公钥
+	oidc, err := utils.NewOIDCVerifier(cfg.JWT.GitHubOIDC)
+	if err != nil {
+		log.Fatalf("Failed to initialize GitHub OIDC verifier: %v", err)
+	}
+
+	// 启用客户端证书认证时加载证书映射
+	certs, err := utils.LoadClientCertMapper(cfg.TLS)
+	if err != nil {
+		log.Fatalf("Failed to load client certificate map: %v", err)
+	}
+
 	// 初始化数据库
 	db, err := database.Init(cfg.Database.URL)
 	if err != nil {
@@ -27,11 +56,15 @@ func main() {
 	defer db.Close()
 
 	// 创建应用
-	application := app.New(cfg, db)
+	application := app.New(cfg, db, keys, oidc, certs)
 
 	// 启动服务器
 	logger.Info("MenthaMC WebAPI serve (Powered by Gin)")
-	logger.Info(fmt.Sprintf("> Ready! Available at http://localhost:%d", cfg.Port))
+	scheme := "http"
+	if cfg.TLS.CertFile != "" {
+		scheme = "https"
+	}
+	logger.Info(fmt.Sprintf("> Ready! Available at %s://localhost:%d", scheme, cfg.Port))
 
 	if err := application.Run(fmt.Sprintf(":%d", cfg.Port)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
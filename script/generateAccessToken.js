@@ -1,4 +1,6 @@
+// Prefer `webapi token issue`, which reads the same configuration as the server.
 const readline = require("readline");
+const { randomUUID } = require("crypto");
 const jwt = require("jsonwebtoken");
 
 const audience = "/v2/commit/build/download_source"; // or other api endpoint, or "*" for all endpoints
@@ -6,6 +8,9 @@ const issuer = "MenthaMC"; // when modify here, also modify in the server enviro
 const subject = "mentha-ci"; // when modify here, also modify in the server environment variable API_SUBJECT
 const algorithm = "ES256"; // when modify here, also modify in the server environment variable API_ALGO. supported: https://github.com/auth0/node-jsonwebtoken#algorithms-supported
 const expiration = "10y";
+const keyId = ""; // kid of the signing key in the server's JWKS (API_JWKS_PATH), leave empty when using API_PUBLIC_KEY only
+const projects = ["*"]; // projects this token may write to, or ["*"] for all projects
+const scopes = ["commit_build", "manage_download_sources"]; // supported: commit_build, manage_download_sources, delete, admin
 
 function input(callback) {
     const rl = readline.createInterface({
@@ -33,13 +38,16 @@ function input(callback) {
 input((key) => {
     const privateKey = key.trim();
     try {
-        const token = jwt.sign({}, privateKey, {
+        const token = jwt.sign({ projects: projects, scopes: scopes }, privateKey, {
             algorithm: algorithm,
             expiresIn: expiration,
             issuer: issuer,
             audience: audience,
-            subject: subject
+            subject: subject,
+            jwtid: randomUUID(), // record this id to revoke the token later via /v2/admin/tokens/revoke
+            ...(keyId ? { keyid: keyId } : {})
         });
+        console.log("Token ID (jti): " + jwt.decode(token).jti);
         console.log("Token: \n" + token);
     } catch (e) {
         console.error("Failed to generate token: ", e.message);
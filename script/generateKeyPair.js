@@ -1,3 +1,4 @@
+// Prefer `webapi keygen`, which reads the same configuration as the server.
 const { generateKeyPairSync } = require("crypto");
 
 const { publicKey, privateKey } = generateKeyPairSync("ec", {
@@ -4,28 +4,40 @@ create table general
 );
 
 insert into general
-values (1);
+values (13);
 
 create table projects
 (
-    id   text primary key,
-    name text not null,
-    repo text not null
+    id                 text primary key,
+    name               text        not null,
+    repo               text        not null,
+    updated_at         timestamptz not null default now(),
+    version_scheme     text        not null default 'minecraft',
+    auto_provision     bool        not null default false,
+    version_group_rule text        not null default '^(\d+\.\d+)'
 );
 
 create table version_groups
 (
     id      serial primary key,
     project text references projects (id) not null,
-    name    text                          not null
+    name    text                          not null,
+    unique (project, name)
 );
 
 create table versions
 (
-    id            serial primary key,
-    name          text                               not null,
-    project       text references projects (id)      not null,
-    version_group int references version_groups (id) not null
+    id               serial primary key,
+    name             text                               not null,
+    project          text references projects (id)      not null,
+    version_group    int references version_groups (id) not null,
+    release_date     date,
+    status           text                               not null default 'supported'
+        check (status in ('supported', 'deprecated', 'eol')),
+    java_minimum     int,
+    java_recommended int,
+    notice           text                               not null default '',
+    unique (project, name)
 );
 
 create table builds
@@ -37,14 +49,17 @@ create table builds
     experimental     bool                          not null,
     jar_name         text                          not null,
     sha256           text                          not null,
+    sha512           text                          not null default '',
     version          int references versions (id)  not null,
     tag              text                          not null,
     changes          int[]                         not null,
-    download_sources text[]                        not null
+    download_sources text[]                        not null,
+    private          bool                          not null default false
 );
 
 create index idx_builds_version_build_id on builds (version, build_id desc);
 create index idx_builds_changes_gin on builds using gin (changes);
+create index idx_builds_project_sha256 on builds (project, lower(sha256));
 
 create table changes
 (
@@ -64,4 +79,72 @@ create table downloads
     tag             text                          not null,
     download_source text                          not null,
     url             text                          not null
+);
+
+create table download_stats
+(
+    id              bigserial primary key,
+    project         text references projects (id) not null,
+    version         text                          not null,
+    build_id        int                           not null,
+    download_source text                          not null,
+    bucket          timestamptz                   not null,
+    user_agent      text                          not null,
+    count           bigint                        not null default 0,
+    unique (project, version, build_id, download_source, bucket, user_agent)
+);
+
+create index idx_download_stats_project_bucket on download_stats (project, bucket);
+
+create table revoked_tokens
+(
+    jti        text primary key,
+    reason     text        not null default '',
+    revoked_at timestamptz not null,
+    expires_at timestamptz
+);
+
+create table audit_log
+(
+    id          bigserial primary key,
+    time        timestamptz not null,
+    subject     text        not null,
+    jti         text        not null,
+    ip          text        not null,
+    method      text        not null,
+    endpoint    text        not null,
+    body_sha256 text        not null,
+    status      int         not null,
+    result      text        not null
+);
+
+create index idx_audit_log_subject on audit_log (subject);
+create index idx_audit_log_jti on audit_log (jti);
+create index idx_audit_log_time on audit_log (time);
+
+create function audit_log_append_only() returns trigger as
+$$
+begin
+    raise exception 'audit_log is append-only';
+end;
+$$ language plpgsql;
+
+create trigger audit_log_append_only
+    before update or delete
+    on audit_log
+    for each row
+execute function audit_log_append_only();
+
+create table service_accounts
+(
+    id           serial primary key,
+    name         text        not null unique,
+    key_prefix   text        not null,
+    key_hash     text        not null unique,
+    projects     text[]      not null default '{}',
+    scopes       text[]      not null default '{}',
+    created_at   timestamptz not null,
+    rotated_at   timestamptz,
+    last_used_at timestamptz,
+    expires_at   timestamptz
 );
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table projects
+    add column version_scheme text not null default 'minecraft';
\ No newline at end of file
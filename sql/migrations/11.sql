@@ -0,0 +1,7 @@
+alter table versions
+    add column release_date     date,
+    add column status           text not null default 'supported'
+        check (status in ('supported', 'deprecated', 'eol')),
+    add column java_minimum     int,
+    add column java_recommended int,
+    add column notice           text not null default '';
\ No newline at end of file
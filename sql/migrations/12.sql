@@ -0,0 +1,34 @@
+do $$
+declare
+    duplicates text;
+begin
+    select string_agg(format('%s/%s (%s)', project, name, count), ', ')
+    into duplicates
+    from (
+        select project, name, count(*) as count
+        from version_groups
+        group by project, name
+        having count(*) > 1
+    ) d;
+    if duplicates is not null then
+        raise exception 'Duplicate version groups must be renamed or merged before migration 12: %', duplicates;
+    end if;
+
+    select string_agg(format('%s/%s (%s)', project, name, count), ', ')
+    into duplicates
+    from (
+        select project, name, count(*) as count
+        from versions
+        group by project, name
+        having count(*) > 1
+    ) d;
+    if duplicates is not null then
+        raise exception 'Duplicate versions must be renamed or merged before migration 12: %', duplicates;
+    end if;
+end $$;
+
+alter table version_groups
+    add constraint version_groups_project_name_key unique (project, name);
+
+alter table versions
+    add constraint versions_project_name_key unique (project, name);
\ No newline at end of file
@@ -0,0 +1,3 @@
+alter table projects
+    add column auto_provision     bool not null default false,
+    add column version_group_rule text not null default '^(\d+\.\d+)';
\ No newline at end of file
@@ -0,0 +1,14 @@
+create table download_stats
+(
+    id              bigserial primary key,
+    project         text references projects (id) not null,
+    version         text                          not null,
+    build_id        int                           not null,
+    download_source text                          not null,
+    bucket          timestamptz                   not null,
+    user_agent      text                          not null,
+    count           bigint                        not null default 0,
+    unique (project, version, build_id, download_source, bucket, user_agent)
+);
+
+create index idx_download_stats_project_bucket on download_stats (project, bucket);
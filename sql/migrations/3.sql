@@ -0,0 +1,2 @@
+alter table builds
+    add column private bool not null default false;
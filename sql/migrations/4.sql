@@ -0,0 +1,2 @@
+alter table builds
+    add column sha512 text not null default '';
@@ -0,0 +1 @@
+create index idx_builds_project_sha256 on builds (project, lower(sha256));
@@ -0,0 +1,7 @@
+create table revoked_tokens
+(
+    jti        text primary key,
+    reason     text        not null default '',
+    revoked_at timestamptz not null,
+    expires_at timestamptz
+);
@@ -0,0 +1,30 @@
+create table audit_log
+(
+    id          bigserial primary key,
+    time        timestamptz not null,
+    subject     text        not null,
+    jti         text        not null,
+    ip          text        not null,
+    method      text        not null,
+    endpoint    text        not null,
+    body_sha256 text        not null,
+    status      int         not null,
+    result      text        not null
+);
+
+create index idx_audit_log_subject on audit_log (subject);
+create index idx_audit_log_jti on audit_log (jti);
+create index idx_audit_log_time on audit_log (time);
+
+create function audit_log_append_only() returns trigger as
+$$
+begin
+    raise exception 'audit_log is append-only';
+end;
+$$ language plpgsql;
+
+create trigger audit_log_append_only
+    before update or delete
+    on audit_log
+    for each row
+execute function audit_log_append_only();
@@ -0,0 +1,13 @@
+create table service_accounts
+(
+    id           serial primary key,
+    name         text        not null unique,
+    key_prefix   text        not null,
+    key_hash     text        not null unique,
+    projects     text[]      not null default '{}',
+    scopes       text[]      not null default '{}',
+    created_at   timestamptz not null,
+    rotated_at   timestamptz,
+    last_used_at timestamptz,
+    expires_at   timestamptz
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table projects
+    add column updated_at timestamptz not null default now();
\ No newline at end of file